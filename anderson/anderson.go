@@ -2,65 +2,103 @@ package anderson
 
 import (
 	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
 	"golang.org/x/exp/constraints"
 )
 
-type AndersonTree[T constraints.Ordered] struct {
+var _ bstrees.Tree[int] = (*AndersonTree[int])(nil)
+
+// AndersonTree must be created by a constructor such as New or NewFunc.
+type AndersonTree[T any] struct {
 	root *andersonTreeNode[T]
+	cmp  func(a, b T) int
+	options
+	counter *stats.Counter // Shared with the trees made from this one
+}
+
+func New[T constraints.Ordered](opts ...Option) *AndersonTree[T] {
+	return NewFunc(bstrees.Compare[T], opts...)
+}
+
+// NewFunc creates a tree ordered by cmp, which returns a negative number when
+// a < b, a positive number when a > b and zero when a == b.
+func NewFunc[T any](cmp func(a, b T) int, opts ...Option) *AndersonTree[T] {
+	counter := new(stats.Counter)
+	return &AndersonTree[T]{root: nil, cmp: stats.Compare(counter, cmp), options: newOptions(opts), counter: counter}
+}
+
+// FromSorted builds a tree from values sorted in ascending order in O(n).
+// The order of values is not checked.
+func FromSorted[T constraints.Ordered](values []T, opts ...Option) *AndersonTree[T] {
+	return FromSortedFunc(bstrees.Compare[T], values, opts...)
 }
 
-func New[T constraints.Ordered]() AndersonTree[T] {
-	return AndersonTree[T]{root: nil}
+// FromSortedFunc builds a tree ordered by cmp from values sorted by cmp in
+// O(n), see NewFunc and FromSorted.
+func FromSortedFunc[T any](cmp func(a, b T) int, values []T, opts ...Option) *AndersonTree[T] {
+	t := NewFunc(cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
+	}
+	t.root = fromSorted(values)
+	return t
 }
 
-func insert[T constraints.Ordered](root *andersonTreeNode[T], value T) *andersonTreeNode[T] {
+// Insert value into root, unless unique is set and value is already present.
+// Returns the new root, the node holding value and whether value has been
+// inserted.
+func insert[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int, unique bool, counter *stats.Counter) (*andersonTreeNode[T], *andersonTreeNode[T], bool) {
 	if root == nil {
-		return newAndersonTreeNode(value, 1)
+		root = newAndersonTreeNode(value, 1)
+		return root, root, true
 	}
-	if value < root.value {
-		root.left = insert(root.left, value)
-	} else {
-		root.right = insert(root.right, value)
+	node := root
+	inserted := false
+	if c := cmp(value, root.value); c < 0 {
+		root.left, node, inserted = insert(root.left, value, cmp, unique, counter)
+	} else if c > 0 || !unique {
+		root.right, node, inserted = insert(root.right, value, cmp, unique, counter)
+	}
+	if !inserted {
+		return root, node, false
 	}
 	root.update()
-	root = skew(root)
-	root = split(root)
-	return root
+	root = skew(root, counter)
+	root = split(root, counter)
+	return root, node, true
 }
 
-func delete[T constraints.Ordered](root *andersonTreeNode[T], value T) *andersonTreeNode[T] {
+// Delete one copy of value from root, returns the new root and whether value
+// has been deleted
+func delete[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int, counter *stats.Counter) (*andersonTreeNode[T], bool) {
 	if root == nil {
-		return nil
+		return nil, false
 	}
-	if value < root.value {
-		root.left = delete(root.left, value)
-	} else if value > root.value {
-		root.right = delete(root.right, value)
+	deleted := true
+	if c := cmp(value, root.value); c < 0 {
+		root.left, deleted = delete(root.left, value, cmp, counter)
+	} else if c > 0 {
+		root.right, deleted = delete(root.right, value, cmp, counter)
 	} else {
 		if root.left == nil {
-			return root.right
+			return root.right, true
 		} else if root.right == nil {
-			return root.left
+			return root.left, true
 		} else {
 			minNode := at(root.right, 1)
 			root.value = minNode.value
-			root.right = delete(root.right, minNode.value)
+			root.right, _ = delete(root.right, minNode.value, cmp, counter)
 		}
 	}
-	root.update()
-	if (root.left != nil && root.left.level < root.level-1) ||
-		(root.right != nil && root.right.level < root.level-1) {
-		root.level -= 1
-		if root.right != nil && root.right.level > root.level {
-			root.right.level = root.level
-		}
-		root = skew(root)
-		root = split(root)
+	if !deleted {
+		return root, false
 	}
-	return root
+	root.update()
+	return rebalance(root, counter), true
 }
 
-func at[T constraints.Ordered](root *andersonTreeNode[T], k uint) *andersonTreeNode[T] {
+func at[T any](root *andersonTreeNode[T], k uint) *andersonTreeNode[T] {
 	for root != nil {
 		leftSize := uint(0)
 		if root.left != nil {
@@ -78,18 +116,21 @@ func at[T constraints.Ordered](root *andersonTreeNode[T], k uint) *andersonTreeN
 	return nil
 }
 
-func (t *AndersonTree[T]) Insert(value T) {
-	t.root = insert(t.root, value)
+func (t *AndersonTree[T]) Insert(value T) bool {
+	var inserted bool
+	t.root, _, inserted = insert(t.root, value, t.cmp, t.unique, t.counter)
+	return inserted
 }
 
 func (t *AndersonTree[T]) Delete(value T) {
-	t.root = delete(t.root, value)
+	t.root, _ = delete(t.root, value, t.cmp, t.counter)
 }
 
 func (t *AndersonTree[T]) At(k uint) (T, error) {
 	root := at(t.root, k)
 	if root == nil {
-		return T(rune(0)), bstrees.ErrIndexIsOutOfRange
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
 	}
 	return root.value, nil
 }
@@ -109,11 +150,11 @@ func (t *AndersonTree[T]) Clear() {
 	t.root = nil
 }
 
-func search[T constraints.Ordered](root *andersonTreeNode[T], value T) *andersonTreeNode[T] {
+func search[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int) *andersonTreeNode[T] {
 	for root != nil {
-		if value < root.value {
+		if c := cmp(value, root.value); c < 0 {
 			root = root.left
-		} else if root.value < value {
+		} else if c > 0 {
 			root = root.right
 		} else {
 			return root
@@ -123,13 +164,13 @@ func search[T constraints.Ordered](root *andersonTreeNode[T], value T) *anderson
 }
 
 func (t *AndersonTree[T]) Contains(value T) bool {
-	return search(t.root, value) != nil
+	return search(t.root, value, t.cmp) != nil
 }
 
-func index[T constraints.Ordered](root *andersonTreeNode[T], value T) uint {
+func index[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int) uint {
 	rank := uint(0)
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			rank += 1
 			if root.left != nil {
 				rank += root.left.size
@@ -143,13 +184,13 @@ func index[T constraints.Ordered](root *andersonTreeNode[T], value T) uint {
 }
 
 func (t *AndersonTree[T]) Index(value T) uint {
-	return index(t.root, value)
+	return index(t.root, value, t.cmp)
 }
 
-func predecessor[T constraints.Ordered](root *andersonTreeNode[T], value T) *andersonTreeNode[T] {
+func predecessor[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int) *andersonTreeNode[T] {
 	var prev *andersonTreeNode[T] = nil
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			prev = root
 			root = root.right
 		} else {
@@ -160,17 +201,18 @@ func predecessor[T constraints.Ordered](root *andersonTreeNode[T], value T) *and
 }
 
 func (t *AndersonTree[T]) Predecessor(value T) (T, error) {
-	prev := predecessor(t.root, value)
+	prev := predecessor(t.root, value, t.cmp)
 	if prev == nil {
-		return T(rune(0)), bstrees.ErrPredecessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
 	}
 	return prev.value, nil
 }
 
-func successor[T constraints.Ordered](root *andersonTreeNode[T], value T) *andersonTreeNode[T] {
+func successor[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int) *andersonTreeNode[T] {
 	var next *andersonTreeNode[T] = nil
 	for root != nil {
-		if root.value > value {
+		if cmp(root.value, value) > 0 {
 			next = root
 			root = root.left
 		} else {
@@ -181,9 +223,187 @@ func successor[T constraints.Ordered](root *andersonTreeNode[T], value T) *ander
 }
 
 func (t *AndersonTree[T]) Successor(value T) (T, error) {
-	prev := successor(t.root, value)
+	prev := successor(t.root, value, t.cmp)
 	if prev == nil {
-		return T(rune(0)), bstrees.ErrSuccessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
 	return prev.value, nil
 }
+
+func floor[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int) *andersonTreeNode[T] {
+	var result *andersonTreeNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c < 0 {
+			result = root
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return result
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *AndersonTree[T]) Floor(value T) (T, bool) {
+	result := floor(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func ceiling[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int) *andersonTreeNode[T] {
+	var result *andersonTreeNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c > 0 {
+			result = root
+			root = root.left
+		} else {
+			root = root.right
+		}
+	}
+	return result
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *AndersonTree[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func minimum[T any](root *andersonTreeNode[T]) *andersonTreeNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.left != nil {
+		root = root.left
+	}
+	return root
+}
+
+func maximum[T any](root *andersonTreeNode[T]) *andersonTreeNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.right != nil {
+		root = root.right
+	}
+	return root
+}
+
+// Remove the smallest node of a non-empty tree, returns the new root and the
+// removed node
+func deleteMin[T any](root *andersonTreeNode[T], counter *stats.Counter) (*andersonTreeNode[T], *andersonTreeNode[T]) {
+	if root.left == nil {
+		return root.right, root
+	}
+	var removed *andersonTreeNode[T]
+	root.left, removed = deleteMin(root.left, counter)
+	root.update()
+	return rebalance(root, counter), removed
+}
+
+// Remove the greatest node of a non-empty tree, returns the new root and the
+// removed node
+func deleteMax[T any](root *andersonTreeNode[T], counter *stats.Counter) (*andersonTreeNode[T], *andersonTreeNode[T]) {
+	if root.right == nil {
+		return root.left, root
+	}
+	var removed *andersonTreeNode[T]
+	root.right, removed = deleteMax(root.right, counter)
+	root.update()
+	return rebalance(root, counter), removed
+}
+
+// Min returns the smallest value.
+func (t *AndersonTree[T]) Min() (T, bool) {
+	result := minimum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *AndersonTree[T]) Max() (T, bool) {
+	result := maximum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *AndersonTree[T]) PopMin() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed *andersonTreeNode[T]
+	t.root, removed = deleteMin(t.root, t.counter)
+	return removed.value, true
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *AndersonTree[T]) PopMax() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed *andersonTreeNode[T]
+	t.root, removed = deleteMax(t.root, t.counter)
+	return removed.value, true
+}
+
+// Rank of the least value greater than value
+func upperIndex[T any](root *andersonTreeNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) <= 0 {
+			rank += 1
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Count returns the number of copies of value.
+func (t *AndersonTree[T]) Count(value T) uint {
+	first, last := t.EqualRange(value)
+	return last - first
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *AndersonTree[T]) EqualRange(value T) (first, last uint) {
+	return index(t.root, value, t.cmp), upperIndex(t.root, value, t.cmp)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *AndersonTree[T]) DeleteAll(value T) uint {
+	left, right := splitBy(t.root, func(v T) bool {
+		return t.cmp(v, value) <= 0
+	}, t.counter)
+	left, mid := splitBy(left, func(v T) bool {
+		return t.cmp(v, value) < 0
+	}, t.counter)
+	t.root = join2(left, right, t.counter)
+	return size(mid)
+}
@@ -0,0 +1,90 @@
+package anderson_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/anderson"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+)
+
+func TestTree(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return anderson.New[int]() })
+}
+
+func TestTreeFunc(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return anderson.NewFunc(func(a, b int) int { return a - b })
+	})
+}
+
+func TestMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return anderson.NewMap[int, int]() })
+}
+
+func TestSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return anderson.New[int](anderson.Unique()) })
+}
+
+func TestFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return anderson.FromSorted(values) })
+}
+
+func TestFromSortedUnique(t *testing.T) {
+	tree := anderson.FromSorted([]int{1, 1, 2, 3, 3, 3}, anderson.Unique())
+	if size := tree.Size(); size != 3 {
+		t.Errorf("Size() = %d, want 3", size)
+	}
+	if tree.Insert(2) {
+		t.Error("Insert(2) = true, want false")
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *anderson.AndersonTree[int] { return anderson.New[int]() },
+		(*anderson.AndersonTree[int]).SplitAt, (*anderson.AndersonTree[int]).SplitRank, anderson.Join[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *anderson.AndersonTree[int] { return anderson.New[int]() },
+		anderson.Union[int], anderson.Intersection[int], anderson.Difference[int])
+}
+
+func TestSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *anderson.AndersonTree[int] { return anderson.New[int](anderson.Unique()) },
+		anderson.Union[int], anderson.Intersection[int], anderson.Difference[int])
+}
+
+func TestEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *anderson.AndersonTree[int] { return anderson.New[int]() },
+		func() *anderson.AndersonTree[int] { return anderson.New[int](anderson.PreserveShape()) })
+}
+
+func TestString(t *testing.T) {
+	tree := anderson.FromSorted([]int{1, 2, 3})
+	want := `2 (level=2)
+├── 1 (level=1)
+└── 3 (level=1)`
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := anderson.New[int]()
+	data := `{"nodes":[{"value":1,"right":true,"level":1},{"value":2,"right":true,"level":1},{"value":3,"level":1}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 1: right grandchild has level 1 under level 1") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 1: right grandchild has level 1 under level 1")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func BenchmarkRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return anderson.New[int]() })
+}
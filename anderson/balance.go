@@ -1,8 +1,13 @@
 package anderson
 
-import "golang.org/x/exp/constraints"
+import (
+	"math/bits"
 
-func leftRotate[T constraints.Ordered](root *andersonTreeNode[T]) *andersonTreeNode[T] {
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
+)
+
+func leftRotate[T any](root *andersonTreeNode[T], counter *stats.Counter) *andersonTreeNode[T] {
+	counter.Rotation()
 	right := root.right
 	root.right = right.left
 	right.left = root
@@ -11,7 +16,8 @@ func leftRotate[T constraints.Ordered](root *andersonTreeNode[T]) *andersonTreeN
 	return right
 }
 
-func rightRotate[T constraints.Ordered](root *andersonTreeNode[T]) *andersonTreeNode[T] {
+func rightRotate[T any](root *andersonTreeNode[T], counter *stats.Counter) *andersonTreeNode[T] {
+	counter.Rotation()
 	left := root.left
 	root.left = left.right
 	left.right = root
@@ -20,18 +26,69 @@ func rightRotate[T constraints.Ordered](root *andersonTreeNode[T]) *andersonTree
 	return left
 }
 
-func skew[T constraints.Ordered](root *andersonTreeNode[T]) *andersonTreeNode[T] {
+func skew[T any](root *andersonTreeNode[T], counter *stats.Counter) *andersonTreeNode[T] {
 	if root.left == nil || root.left.level != root.level {
 		return root
 	}
-	return rightRotate(root)
+	counter.Skew()
+	return rightRotate(root, counter)
 }
 
-func split[T constraints.Ordered](root *andersonTreeNode[T]) *andersonTreeNode[T] {
+func split[T any](root *andersonTreeNode[T], counter *stats.Counter) *andersonTreeNode[T] {
 	if root.right == nil || root.right.right == nil || root.right.right.level != root.level {
 		return root
 	}
-	root = leftRotate(root)
-	root.right.level += 1
+	counter.LevelSplit()
+	root = leftRotate(root, counter)
+	root.level += 1
+	return root
+}
+
+func level[T any](root *andersonTreeNode[T]) uint {
+	if root == nil {
+		return 0
+	}
+	return root.level
+}
+
+// Restore the levels after a node has been removed below root
+func rebalance[T any](root *andersonTreeNode[T], counter *stats.Counter) *andersonTreeNode[T] {
+	want := level(root.left)
+	if right := level(root.right); right < want {
+		want = right
+	}
+	want += 1
+	if want < root.level {
+		root.level = want
+		if root.right != nil && root.right.level > want {
+			root.right.level = want
+		}
+	}
+	root = skew(root, counter)
+	if root.right != nil {
+		root.right = skew(root.right, counter)
+		if root.right.right != nil {
+			root.right.right = skew(root.right.right, counter)
+		}
+	}
+	root = split(root, counter)
+	if root.right != nil {
+		root.right = split(root.right, counter)
+	}
+	return root
+}
+
+// Build a tree from sorted values. A tree of n nodes gets the level
+// floor(log2(n+1)), and the larger half goes to the right so that a left
+// child is always one level below its parent.
+func fromSorted[T any](values []T) *andersonTreeNode[T] {
+	if len(values) == 0 {
+		return nil
+	}
+	mid := (len(values) - 1) / 2
+	root := newAndersonTreeNode(values[mid], uint(bits.Len(uint(len(values)+1))-1))
+	root.left = fromSorted(values[:mid])
+	root.right = fromSorted(values[mid+1:])
+	root.update()
 	return root
 }
@@ -0,0 +1,27 @@
+package anderson
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	tree := New[int]()
+	for i := 0; i < 1024; i++ {
+		tree.Insert(i)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() = %v after 1024 ascending insertions, want nil", err)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		if r.Intn(2) == 0 {
+			tree.Insert(r.Intn(2048))
+		} else {
+			tree.Delete(r.Intn(2048))
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatalf("Validate() = %v, want nil", err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package anderson
+
+import (
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+)
+
+// Append the nodes of root in pre-order
+func encodeNodes[T any](root *andersonTreeNode[T], nodes []codec.Node[T]) []codec.Node[T] {
+	if root == nil {
+		return nodes
+	}
+	nodes = append(nodes, codec.Node[T]{Value: root.value, Left: root.left != nil, Right: root.right != nil, Level: root.level})
+	nodes = encodeNodes(root.left, nodes)
+	return encodeNodes(root.right, nodes)
+}
+
+// Rebuild a node from its serialized form
+func decodeNode[T any](n *codec.Node[T], left, right *andersonTreeNode[T]) *andersonTreeNode[T] {
+	node := &andersonTreeNode[T]{value: n.Value, left: left, right: right, level: n.Level}
+	node.update()
+	return node
+}
+
+func (t *AndersonTree[T]) encode() *codec.Tree[T] {
+	tree := new(codec.Tree[T])
+	if t.shape {
+		tree.Nodes = encodeNodes(t.root, make([]codec.Node[T], 0, t.Size()))
+	} else {
+		tree.Values = make([]T, 0, t.Size())
+		ascend(t.root, func(value T) bool {
+			tree.Values = append(tree.Values, value)
+			return true
+		})
+	}
+	return tree
+}
+
+func (t *AndersonTree[T]) decode(tree *codec.Tree[T]) error {
+	if t.cmp == nil {
+		return bstrees.ErrTreeIsNotInitialized
+	}
+	if len(tree.Nodes) != 0 {
+		root, err := codec.Build(tree.Nodes, decodeNode[T])
+		if err != nil {
+			return err
+		}
+		if err := (&AndersonTree[T]{root: root, cmp: t.cmp, options: t.options, counter: t.counter}).Validate(); err != nil {
+			return codec.Corrupted(err)
+		}
+		t.root = root
+		return nil
+	}
+	if !codec.Sorted(tree.Values, t.cmp, false) {
+		return bstrees.ErrDataIsCorrupted
+	}
+	values := tree.Values
+	if t.unique {
+		values = codec.Unique(values, t.cmp)
+	}
+	t.root = fromSorted(values)
+	return nil
+}
+
+// MarshalBinary encodes the values of t in ascending order, or its nodes with
+// their levels if t has been created with PreserveShape.
+func (t *AndersonTree[T]) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(t.encode())
+}
+
+// UnmarshalBinary replaces the values of t with the ones encoded by
+// MarshalBinary, in O(n) whichever the encoding. t must have been created by a
+// constructor, and keeps its own options.
+func (t *AndersonTree[T]) UnmarshalBinary(data []byte) error {
+	tree, err := codec.UnmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+// MarshalJSON encodes t like MarshalBinary, as a JSON object.
+func (t *AndersonTree[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(t.encode())
+}
+
+// UnmarshalJSON decodes t like UnmarshalBinary.
+func (t *AndersonTree[T]) UnmarshalJSON(data []byte) error {
+	tree, err := codec.UnmarshalJSON[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+func (t *AndersonTree[T]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+func (t *AndersonTree[T]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
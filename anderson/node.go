@@ -1,8 +1,6 @@
 package anderson
 
-import "golang.org/x/exp/constraints"
-
-type andersonTreeNode[T constraints.Ordered] struct {
+type andersonTreeNode[T any] struct {
 	value T
 	left  *andersonTreeNode[T]
 	right *andersonTreeNode[T]
@@ -10,7 +8,7 @@ type andersonTreeNode[T constraints.Ordered] struct {
 	level uint
 }
 
-func newAndersonTreeNode[T constraints.Ordered](value T, level uint) *andersonTreeNode[T] {
+func newAndersonTreeNode[T any](value T, level uint) *andersonTreeNode[T] {
 	return &andersonTreeNode[T]{
 		value: value,
 		left:  nil,
@@ -0,0 +1,29 @@
+package anderson
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yanglinshu/bstrees/v2/internal/render"
+)
+
+func describe[T any](root *andersonTreeNode[T]) *render.Node {
+	if root == nil {
+		return nil
+	}
+	n := &render.Node{Value: fmt.Sprint(root.value), Meta: fmt.Sprintf("level=%d", root.level)}
+	n.Left = describe(root.left)
+	n.Right = describe(root.right)
+	return n
+}
+
+// WriteDOT writes the structure of t as a Graphviz digraph, each node being
+// labeled with its level.
+func (t *AndersonTree[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, describe(t.root))
+}
+
+// String draws the structure of t as text, see WriteDOT.
+func (t *AndersonTree[T]) String() string {
+	return render.String(describe(t.root))
+}
@@ -0,0 +1,101 @@
+package anderson
+
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
+
+func size[T any](root *andersonTreeNode[T]) uint {
+	if root == nil {
+		return 0
+	}
+	return root.size
+}
+
+// Join left, the single node middle and right, where left <= middle <= right
+func join3[T any](left, middle, right *andersonTreeNode[T], counter *stats.Counter) *andersonTreeNode[T] {
+	if level(left) > level(right) {
+		left.right = join3(left.right, middle, right, counter)
+		left.update()
+		return split(skew(left, counter), counter)
+	}
+	if level(right) > level(left) {
+		right.left = join3(left, middle, right.left, counter)
+		right.update()
+		return split(skew(right, counter), counter)
+	}
+	middle.left = left
+	middle.right = right
+	middle.level = level(left) + 1
+	middle.update()
+	return middle
+}
+
+// Join left and right, where left <= right
+func join2[T any](left, right *andersonTreeNode[T], counter *stats.Counter) *andersonTreeNode[T] {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	middle, right := splitRank(right, 1, counter)
+	return join3(left, middle, right, counter)
+}
+
+// Split root into the values for which left returns true and the others,
+// where left returns true for a prefix of the values
+func splitBy[T any](root *andersonTreeNode[T], left func(value T) bool, counter *stats.Counter) (*andersonTreeNode[T], *andersonTreeNode[T]) {
+	if root == nil {
+		return nil, nil
+	}
+	rootLeft, rootRight := root.left, root.right
+	if left(root.value) {
+		rightLeft, rightRight := splitBy(rootRight, left, counter)
+		return join3(rootLeft, root, rightLeft, counter), rightRight
+	} else {
+		leftLeft, leftRight := splitBy(rootLeft, left, counter)
+		return leftLeft, join3(leftRight, root, rootRight, counter)
+	}
+}
+
+// Split root into the k smallest values and the others
+func splitRank[T any](root *andersonTreeNode[T], k uint, counter *stats.Counter) (*andersonTreeNode[T], *andersonTreeNode[T]) {
+	if root == nil {
+		return nil, nil
+	}
+	left, right := root.left, root.right
+	if leftSize := size(left); k <= leftSize {
+		leftLeft, leftRight := splitRank(left, k, counter)
+		return leftLeft, join3(leftRight, root, right, counter)
+	} else {
+		rightLeft, rightRight := splitRank(right, k-leftSize-1, counter)
+		return join3(left, root, rightLeft, counter), rightRight
+	}
+}
+
+// SplitAt moves the values less than value to left and the others to right
+// in O(log n). t is left empty.
+func (t *AndersonTree[T]) SplitAt(value T) (left, right *AndersonTree[T]) {
+	l, r := splitBy(t.root, func(v T) bool {
+		return t.cmp(v, value) < 0
+	}, t.counter)
+	t.root = nil
+	return &AndersonTree[T]{root: l, cmp: t.cmp, options: t.options, counter: t.counter}, &AndersonTree[T]{root: r, cmp: t.cmp, options: t.options, counter: t.counter}
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// O(log n). t is left empty.
+func (t *AndersonTree[T]) SplitRank(k uint) (left, right *AndersonTree[T]) {
+	l, r := splitRank(t.root, k, t.counter)
+	t.root = nil
+	return &AndersonTree[T]{root: l, cmp: t.cmp, options: t.options, counter: t.counter}, &AndersonTree[T]{root: r, cmp: t.cmp, options: t.options, counter: t.counter}
+}
+
+// Join moves the values of left and right to a new tree in O(log n). Every
+// value of left must be less than every value of right, or equal to it if
+// the trees are not sets, which is not checked. The new tree is ordered like
+// left, and left and right are left empty.
+func Join[T any](left, right *AndersonTree[T]) *AndersonTree[T] {
+	result := &AndersonTree[T]{root: join2(left.root, right.root, left.counter), cmp: left.cmp, options: left.options, counter: left.counter}
+	left.root = nil
+	right.root = nil
+	return result
+}
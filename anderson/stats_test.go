@@ -0,0 +1,45 @@
+//go:build bstrees_stats
+
+package anderson_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/anderson"
+)
+
+func TestStats(t *testing.T) {
+	tree := anderson.New[int]()
+	other := anderson.New[int]()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		tree.Insert(r.Intn(500))
+		tree.Delete(r.Intn(500))
+	}
+	if stats := other.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() of another tree = %+v, want zero", stats)
+	}
+	stats := tree.Stats()
+	for _, c := range []struct {
+		name  string
+		count uint64
+	}{
+		{"Comparisons", stats.Comparisons},
+		{"Rotations", stats.Rotations},
+		{"Skews", stats.Skews},
+		{"LevelSplits", stats.LevelSplits},
+	} {
+		if c.count == 0 {
+			t.Errorf("Stats().%s = 0, want > 0", c.name)
+		}
+	}
+	if stats.Splits != 0 {
+		t.Errorf("Stats().Splits = %d, want 0", stats.Splits)
+	}
+	tree.ResetStats()
+	if stats := tree.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() = %+v after ResetStats, want zero", stats)
+	}
+}
@@ -0,0 +1,43 @@
+package anderson
+
+import "github.com/yanglinshu/bstrees/v2/internal/invariant"
+
+func validate[T any](root *andersonTreeNode[T], order *invariant.Order[T]) error {
+	if root == nil {
+		return nil
+	}
+	if err := validate(root.left, order); err != nil {
+		return err
+	}
+	if err := order.Next(root.value); err != nil {
+		return err
+	}
+	if err := validate(root.right, order); err != nil {
+		return err
+	}
+	if want := size(root.left) + size(root.right) + 1; root.size != want {
+		return invariant.Errorf(root.value, "size is %d, want %d", root.size, want)
+	}
+	if root.left == nil && root.right == nil && root.level != 1 {
+		return invariant.Errorf(root.value, "leaf has level %d, want 1", root.level)
+	}
+	if level(root.left)+1 != root.level {
+		return invariant.Errorf(root.value, "left child has level %d under level %d", level(root.left), root.level)
+	}
+	if right := level(root.right); right+1 != root.level && right != root.level {
+		return invariant.Errorf(root.value, "right child has level %d under level %d", right, root.level)
+	}
+	if root.right != nil && level(root.right.right) >= root.level {
+		return invariant.Errorf(root.value, "right grandchild has level %d under level %d", root.right.right.level, root.level)
+	}
+	return nil
+}
+
+// Validate checks the order of the values and, for every node, its cached
+// size and the AA tree rules on levels: a leaf is at level 1, a left child is
+// one level below its parent, a right child is at most one level below its
+// parent and a right grandchild is strictly below its grandparent. Returns a
+// *bstrees.InvariantError naming the first node found to break a rule.
+func (t *AndersonTree[T]) Validate() error {
+	return validate(t.root, invariant.NewOrder(t.cmp, t.unique))
+}
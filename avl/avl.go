@@ -2,18 +2,51 @@ package avl
 
 import (
 	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
 	"golang.org/x/exp/constraints"
 )
 
-type AVLTree[T constraints.Ordered] struct {
+var _ bstrees.Tree[int] = (*AVLTree[int])(nil)
+
+// AVLTree must be created by a constructor such as New or NewFunc.
+type AVLTree[T any] struct {
 	root *avlTreeNode[T]
+	cmp  func(a, b T) int
+	options
+	cow     *copyOnWrite
+	counter *stats.Counter // Shared with the trees made from this one
+}
+
+func New[T constraints.Ordered](opts ...Option) *AVLTree[T] {
+	return NewFunc(bstrees.Compare[T], opts...)
+}
+
+// NewFunc creates a tree ordered by cmp, which returns a negative number when
+// a < b, a positive number when a > b and zero when a == b.
+func NewFunc[T any](cmp func(a, b T) int, opts ...Option) *AVLTree[T] {
+	counter := new(stats.Counter)
+	return &AVLTree[T]{root: nil, cmp: stats.Compare(counter, cmp), options: newOptions(opts), counter: counter}
+}
+
+// FromSorted builds a tree from values sorted in ascending order in O(n).
+// The order of values is not checked.
+func FromSorted[T constraints.Ordered](values []T, opts ...Option) *AVLTree[T] {
+	return FromSortedFunc(bstrees.Compare[T], values, opts...)
 }
 
-func New[T constraints.Ordered]() *AVLTree[T] {
-	return &AVLTree[T]{root: nil}
+// FromSortedFunc builds a tree ordered by cmp from values sorted by cmp in
+// O(n), see NewFunc and FromSorted.
+func FromSortedFunc[T any](cmp func(a, b T) int, values []T, opts ...Option) *AVLTree[T] {
+	t := NewFunc(cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
+	}
+	t.root = fromSorted(values)
+	return t
 }
 
-func at[T constraints.Ordered](root *avlTreeNode[T], k uint) *avlTreeNode[T] {
+func at[T any](root *avlTreeNode[T], k uint) *avlTreeNode[T] {
 	for root != nil {
 		leftSize := uint(0)
 		if root.left != nil {
@@ -31,51 +64,86 @@ func at[T constraints.Ordered](root *avlTreeNode[T], k uint) *avlTreeNode[T] {
 	return nil
 }
 
-func insert[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeNode[T] {
+// Insert value into root, unless unique is set and value is already present.
+// Returns the new root, the node holding value and whether value has been
+// inserted.
+func insert[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int, unique bool, cow *copyOnWrite, counter *stats.Counter) (*avlTreeNode[T], *avlTreeNode[T], bool) {
 	if root == nil {
-		return newAVLTreeNode(value)
+		root = newAVLTreeNode(value, cow)
+		return root, root, true
+	}
+	var child, node *avlTreeNode[T]
+	inserted := false
+	c := cmp(value, root.value)
+	if c < 0 {
+		child, node, inserted = insert(root.left, value, cmp, unique, cow, counter)
+	} else if c > 0 || !unique {
+		child, node, inserted = insert(root.right, value, cmp, unique, cow, counter)
+	} else {
+		node = root
+	}
+	if !inserted {
+		return root, node, false
 	}
-	if value < root.value {
-		root.left = insert(root.left, value)
+	root = root.mutable(cow)
+	if c < 0 {
+		root.left = child
 	} else {
-		root.right = insert(root.right, value)
+		root.right = child
 	}
 	root.update()
-	return balance(root)
+	return balance(root, cow, counter), node, true
 }
 
-func (t *AVLTree[T]) Insert(value T) {
-	t.root = insert(t.root, value)
+func (t *AVLTree[T]) Insert(value T) bool {
+	var inserted bool
+	t.root, _, inserted = insert(t.root, value, t.cmp, t.unique, t.cow, t.counter)
+	return inserted
 }
 
-func delete[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeNode[T] {
+// Delete one copy of value from root, returns the new root and whether value
+// has been deleted
+func delete[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter) (*avlTreeNode[T], bool) {
 	if root == nil {
-		return nil
+		return nil, false
 	}
-	if value < root.value {
-		root.left = delete(root.left, value)
-	} else if root.value < value {
-		root.right = delete(root.right, value)
-	} else {
+	if c := cmp(value, root.value); c == 0 {
 		if root.left == nil {
-			return root.right
+			return root.right, true
 		} else if root.right == nil {
-			return root.left
+			return root.left, true
+		}
+		var minNode *avlTreeNode[T]
+		root = root.mutable(cow)
+		root.right, minNode = deleteMin(root.right, cow, counter)
+		root.value = minNode.value
+	} else {
+		var child *avlTreeNode[T]
+		deleted := false
+		if c < 0 {
+			child, deleted = delete(root.left, value, cmp, cow, counter)
+		} else {
+			child, deleted = delete(root.right, value, cmp, cow, counter)
+		}
+		if !deleted {
+			return root, false
+		}
+		root = root.mutable(cow)
+		if c < 0 {
+			root.left = child
 		} else {
-			minNode := at(root.right, 1) // root.right is not nil, so this will not fail
-			root.value = minNode.value
-			root.right = delete(root.right, minNode.value)
+			root.right = child
 		}
 	}
 	root.update()
-	return balance(root)
+	return balance(root, cow, counter), true
 }
 
-func search[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeNode[T] {
+func search[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int) *avlTreeNode[T] {
 	for root != nil {
-		if value < root.value {
+		if c := cmp(value, root.value); c < 0 {
 			root = root.left
-		} else if root.value < value {
+		} else if c > 0 {
 			root = root.right
 		} else {
 			return root
@@ -85,11 +153,11 @@ func search[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeNode[T
 }
 
 func (t *AVLTree[T]) Delete(value T) {
-	t.root = delete(t.root, value)
+	t.root, _ = delete(t.root, value, t.cmp, t.cow, t.counter)
 }
 
 func (t *AVLTree[T]) Contains(value T) bool {
-	return search(t.root, value) != nil
+	return search(t.root, value, t.cmp) != nil
 }
 
 func (t *AVLTree[T]) Size() uint {
@@ -109,7 +177,8 @@ func (t *AVLTree[T]) Height() int {
 func (t *AVLTree[T]) At(k uint) (T, error) {
 	result := at(t.root, k)
 	if result == nil {
-		return T(rune(0)), bstrees.ErrIndexIsOutOfRange
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
 	}
 	return result.value, nil
 }
@@ -122,10 +191,19 @@ func (t *AVLTree[T]) Clear() {
 	t.root = nil
 }
 
-func index[T constraints.Ordered](root *avlTreeNode[T], value T) uint {
+// Snapshot returns a tree holding the values of t in O(1). The two trees
+// share their nodes, and a node is only copied when either tree modifies it,
+// so the snapshot can be read while t is being modified. Snapshot itself
+// modifies t.
+func (t *AVLTree[T]) Snapshot() *AVLTree[T] {
+	t.cow = new(copyOnWrite)
+	return &AVLTree[T]{root: t.root, cmp: t.cmp, options: t.options, cow: new(copyOnWrite), counter: t.counter}
+}
+
+func index[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int) uint {
 	rank := uint(0)
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			rank += 1
 			if root.left != nil {
 				rank += root.left.size
@@ -139,13 +217,13 @@ func index[T constraints.Ordered](root *avlTreeNode[T], value T) uint {
 }
 
 func (t *AVLTree[T]) Index(value T) uint {
-	return index(t.root, value)
+	return index(t.root, value, t.cmp)
 }
 
-func predecessor[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeNode[T] {
+func predecessor[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int) *avlTreeNode[T] {
 	var result *avlTreeNode[T] = nil
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			result = root
 			root = root.right
 		} else {
@@ -156,17 +234,18 @@ func predecessor[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeN
 }
 
 func (t *AVLTree[T]) Predecessor(value T) (T, error) {
-	prev := predecessor(t.root, value)
+	prev := predecessor(t.root, value, t.cmp)
 	if prev == nil {
-		return T(rune(0)), bstrees.ErrPredecessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
 	}
 	return prev.value, nil
 }
 
-func successor[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeNode[T] {
+func successor[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int) *avlTreeNode[T] {
 	var result *avlTreeNode[T] = nil
 	for root != nil {
-		if root.value > value {
+		if cmp(root.value, value) > 0 {
 			result = root
 			root = root.left
 		} else {
@@ -177,9 +256,189 @@ func successor[T constraints.Ordered](root *avlTreeNode[T], value T) *avlTreeNod
 }
 
 func (t *AVLTree[T]) Successor(value T) (T, error) {
-	next := successor(t.root, value)
+	next := successor(t.root, value, t.cmp)
 	if next == nil {
-		return T(rune(0)), bstrees.ErrSuccessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
 	return next.value, nil
 }
+
+func floor[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int) *avlTreeNode[T] {
+	var result *avlTreeNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c < 0 {
+			result = root
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return result
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *AVLTree[T]) Floor(value T) (T, bool) {
+	result := floor(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func ceiling[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int) *avlTreeNode[T] {
+	var result *avlTreeNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c > 0 {
+			result = root
+			root = root.left
+		} else {
+			root = root.right
+		}
+	}
+	return result
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *AVLTree[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func minimum[T any](root *avlTreeNode[T]) *avlTreeNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.left != nil {
+		root = root.left
+	}
+	return root
+}
+
+func maximum[T any](root *avlTreeNode[T]) *avlTreeNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.right != nil {
+		root = root.right
+	}
+	return root
+}
+
+// Remove the smallest node of a non-empty tree, returns the new root and the
+// removed node
+func deleteMin[T any](root *avlTreeNode[T], cow *copyOnWrite, counter *stats.Counter) (*avlTreeNode[T], *avlTreeNode[T]) {
+	if root.left == nil {
+		return root.right, root
+	}
+	var removed *avlTreeNode[T]
+	root = root.mutable(cow)
+	root.left, removed = deleteMin(root.left, cow, counter)
+	root.update()
+	return balance(root, cow, counter), removed
+}
+
+// Remove the greatest node of a non-empty tree, returns the new root and the
+// removed node
+func deleteMax[T any](root *avlTreeNode[T], cow *copyOnWrite, counter *stats.Counter) (*avlTreeNode[T], *avlTreeNode[T]) {
+	if root.right == nil {
+		return root.left, root
+	}
+	var removed *avlTreeNode[T]
+	root = root.mutable(cow)
+	root.right, removed = deleteMax(root.right, cow, counter)
+	root.update()
+	return balance(root, cow, counter), removed
+}
+
+// Min returns the smallest value.
+func (t *AVLTree[T]) Min() (T, bool) {
+	result := minimum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *AVLTree[T]) Max() (T, bool) {
+	result := maximum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *AVLTree[T]) PopMin() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed *avlTreeNode[T]
+	t.root, removed = deleteMin(t.root, t.cow, t.counter)
+	return removed.value, true
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *AVLTree[T]) PopMax() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed *avlTreeNode[T]
+	t.root, removed = deleteMax(t.root, t.cow, t.counter)
+	return removed.value, true
+}
+
+// Rank of the least value greater than value
+func upperIndex[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) <= 0 {
+			rank += 1
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Count returns the number of copies of value.
+func (t *AVLTree[T]) Count(value T) uint {
+	first, last := t.EqualRange(value)
+	return last - first
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *AVLTree[T]) EqualRange(value T) (first, last uint) {
+	return index(t.root, value, t.cmp), upperIndex(t.root, value, t.cmp)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *AVLTree[T]) DeleteAll(value T) uint {
+	left, right := splitBy(t.root, func(v T) bool {
+		return t.cmp(v, value) <= 0
+	}, t.cow, t.counter)
+	left, mid := splitBy(left, func(v T) bool {
+		return t.cmp(v, value) < 0
+	}, t.cow, t.counter)
+	t.root = join2(left, right, t.cow, t.counter)
+	return size(mid)
+}
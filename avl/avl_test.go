@@ -0,0 +1,95 @@
+package avl_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/avl"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+)
+
+func TestTree(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return avl.New[int]() })
+}
+
+func TestTreeFunc(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return avl.NewFunc(func(a, b int) int { return a - b })
+	})
+}
+
+func TestMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return avl.NewMap[int, int]() })
+}
+
+func TestSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return avl.New[int](avl.Unique()) })
+}
+
+func TestFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return avl.FromSorted(values) })
+}
+
+func TestFromSortedUnique(t *testing.T) {
+	tree := avl.FromSorted([]int{1, 1, 2, 3, 3, 3}, avl.Unique())
+	if size := tree.Size(); size != 3 {
+		t.Errorf("Size() = %d, want 3", size)
+	}
+	if tree.Insert(2) {
+		t.Error("Insert(2) = true, want false")
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *avl.AVLTree[int] { return avl.New[int]() },
+		(*avl.AVLTree[int]).SplitAt, (*avl.AVLTree[int]).SplitRank, avl.Join[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *avl.AVLTree[int] { return avl.New[int]() },
+		avl.Union[int], avl.Intersection[int], avl.Difference[int])
+}
+
+func TestSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *avl.AVLTree[int] { return avl.New[int](avl.Unique()) },
+		avl.Union[int], avl.Intersection[int], avl.Difference[int])
+}
+
+func TestSnapshot(t *testing.T) {
+	bstreestest.RunSnapshotSuite(t, func() *avl.AVLTree[int] { return avl.New[int]() },
+		(*avl.AVLTree[int]).Snapshot, (*avl.AVLTree[int]).SplitAt, avl.Join[int])
+}
+
+func TestEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *avl.AVLTree[int] { return avl.New[int]() },
+		func() *avl.AVLTree[int] { return avl.New[int](avl.PreserveShape()) })
+}
+
+func TestString(t *testing.T) {
+	tree := avl.FromSorted([]int{1, 2, 3})
+	want := `2 (height=1)
+├── 1 (height=0)
+└── 3 (height=0)`
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := avl.New[int]()
+	data := `{"nodes":[{"value":1,"right":true,"height":2},{"value":2,"right":true,"height":1},{"value":3}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 1: balance factor is -2") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 1: balance factor is -2")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func BenchmarkRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return avl.New[int]() })
+}
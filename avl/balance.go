@@ -1,9 +1,11 @@
 package avl
 
-import "golang.org/x/exp/constraints"
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
 
-func leftRotate[T constraints.Ordered](root *avlTreeNode[T]) *avlTreeNode[T] {
-	right := root.right
+func leftRotate[T any](root *avlTreeNode[T], cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
+	counter.Rotation()
+	root = root.mutable(cow)
+	right := root.right.mutable(cow)
 	root.right = right.left
 	right.left = root
 	root.update()
@@ -11,8 +13,10 @@ func leftRotate[T constraints.Ordered](root *avlTreeNode[T]) *avlTreeNode[T] {
 	return right
 }
 
-func rightRotate[T constraints.Ordered](root *avlTreeNode[T]) *avlTreeNode[T] {
-	left := root.left
+func rightRotate[T any](root *avlTreeNode[T], cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
+	counter.Rotation()
+	root = root.mutable(cow)
+	left := root.left.mutable(cow)
 	root.left = left.right
 	left.right = root
 	root.update()
@@ -20,7 +24,8 @@ func rightRotate[T constraints.Ordered](root *avlTreeNode[T]) *avlTreeNode[T] {
 	return left
 }
 
-func balance[T constraints.Ordered](root *avlTreeNode[T]) *avlTreeNode[T] {
+// Rebalance root, which must be mutable
+func balance[T any](root *avlTreeNode[T], cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
 	leftHeight := -1
 	if root.left != nil {
 		leftHeight = root.left.height
@@ -40,9 +45,9 @@ func balance[T constraints.Ordered](root *avlTreeNode[T]) *avlTreeNode[T] {
 			leftRightHeight = left.right.height
 		}
 		if leftLeftHeight < leftRightHeight {
-			root.left = leftRotate(left)
+			root.left = leftRotate(left, cow, counter)
 		}
-		ret := rightRotate(root)
+		ret := rightRotate(root, cow, counter)
 		return ret
 	} else if rightHeight > leftHeight+1 {
 		right := root.right
@@ -55,9 +60,22 @@ func balance[T constraints.Ordered](root *avlTreeNode[T]) *avlTreeNode[T] {
 			rightRightHeight = right.right.height
 		}
 		if rightRightHeight < rightLeftHeight {
-			root.right = rightRotate(right)
+			root.right = rightRotate(right, cow, counter)
 		}
-		return leftRotate(root)
+		return leftRotate(root, cow, counter)
 	}
 	return root
 }
+
+// Build a balanced tree from sorted values
+func fromSorted[T any](values []T) *avlTreeNode[T] {
+	if len(values) == 0 {
+		return nil
+	}
+	mid := len(values) / 2
+	root := newAVLTreeNode(values[mid], nil)
+	root.left = fromSorted(values[:mid])
+	root.right = fromSorted(values[mid+1:])
+	root.update()
+	return root
+}
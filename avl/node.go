@@ -1,21 +1,51 @@
 package avl
 
-import (
-	"math"
+import "math"
 
-	"golang.org/x/exp/constraints"
-)
+// Nodes are shared between a tree and its snapshots. A tree only modifies the
+// nodes created with its own copyOnWrite, and copies the others first.
+type copyOnWrite struct {
+	_ byte // Gives every copyOnWrite a distinct address
+}
 
-type avlTreeNode[T constraints.Ordered] struct {
+type avlTreeNode[T any] struct {
 	value  T
 	left   *avlTreeNode[T]
 	right  *avlTreeNode[T]
 	height int  // Height of the node
 	size   uint // Size of subtree, unnecessary if you don't need kth element
+	cow    *copyOnWrite
+}
+
+func newAVLTreeNode[T any](value T, cow *copyOnWrite) *avlTreeNode[T] {
+	return &avlTreeNode[T]{value: value, left: nil, right: nil, height: 0, size: 1, cow: cow}
+}
+
+// Token for a tree taking over nodes of the tree owning cow. A tree that has
+// never been snapshotted shares no node, and keeps a nil token.
+func (cow *copyOnWrite) fork() *copyOnWrite {
+	if cow == nil {
+		return nil
+	}
+	return new(copyOnWrite)
 }
 
-func newAVLTreeNode[T constraints.Ordered](value T) *avlTreeNode[T] {
-	return &avlTreeNode[T]{value: value, left: nil, right: nil, height: 0, size: 1}
+// Token for a tree made of the nodes of the trees owning a and b
+func joinCOW(a, b *copyOnWrite) *copyOnWrite {
+	if a == nil {
+		return b.fork()
+	}
+	return a.fork()
+}
+
+// Returns n if it can be modified by the tree owning cow, or a copy of n
+func (n *avlTreeNode[T]) mutable(cow *copyOnWrite) *avlTreeNode[T] {
+	if n.cow == cow {
+		return n
+	}
+	clone := *n
+	clone.cow = cow
+	return &clone
 }
 
 func (n *avlTreeNode[T]) update() {
@@ -0,0 +1,29 @@
+package avl
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yanglinshu/bstrees/v2/internal/render"
+)
+
+func describe[T any](root *avlTreeNode[T]) *render.Node {
+	if root == nil {
+		return nil
+	}
+	n := &render.Node{Value: fmt.Sprint(root.value), Meta: fmt.Sprintf("height=%d", root.height)}
+	n.Left = describe(root.left)
+	n.Right = describe(root.right)
+	return n
+}
+
+// WriteDOT writes the structure of t as a Graphviz digraph, each node being
+// labeled with its height.
+func (t *AVLTree[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, describe(t.root))
+}
+
+// String draws the structure of t as text, see WriteDOT.
+func (t *AVLTree[T]) String() string {
+	return render.String(describe(t.root))
+}
@@ -0,0 +1,100 @@
+package avl
+
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
+
+// Split root into the values less than, equal to and greater than value
+func split3[T any](root *avlTreeNode[T], value T, cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter) (less, equal, greater *avlTreeNode[T]) {
+	less, root = splitBy(root, func(v T) bool {
+		return cmp(v, value) < 0
+	}, cow, counter)
+	equal, greater = splitBy(root, func(v T) bool {
+		return cmp(v, value) <= 0
+	}, cow, counter)
+	return less, equal, greater
+}
+
+// Merge a and b around the root value of b, keeping the copies of that value
+// chosen by keep among the copies found in a and in b
+func combine[T any](a, b *avlTreeNode[T], cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter,
+	recurse func(a, b *avlTreeNode[T], cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T],
+	keep func(a, b *avlTreeNode[T]) *avlTreeNode[T]) *avlTreeNode[T] {
+	aLess, aEqual, aGreater := split3(a, b.value, cmp, cow, counter)
+	bLess, bEqual, bGreater := split3(b, b.value, cmp, cow, counter)
+	less := recurse(aLess, bLess, cmp, cow, counter)
+	greater := recurse(aGreater, bGreater, cmp, cow, counter)
+	return join2(less, join2(keep(aEqual, bEqual), greater, cow, counter), cow, counter)
+}
+
+func union[T any](a, b *avlTreeNode[T], cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return combine(a, b, cmp, cow, counter, union[T], func(a, b *avlTreeNode[T]) *avlTreeNode[T] {
+		if size(a) > size(b) {
+			return a
+		}
+		return b
+	})
+}
+
+func intersection[T any](a, b *avlTreeNode[T], cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
+	if a == nil || b == nil {
+		return nil
+	}
+	return combine(a, b, cmp, cow, counter, intersection[T], func(a, b *avlTreeNode[T]) *avlTreeNode[T] {
+		if size(a) < size(b) {
+			return a
+		}
+		return b
+	})
+}
+
+func difference[T any](a, b *avlTreeNode[T], cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
+	if a == nil || b == nil {
+		return a
+	}
+	return combine(a, b, cmp, cow, counter, difference[T], func(a, b *avlTreeNode[T]) *avlTreeNode[T] {
+		if size(a) <= size(b) {
+			return nil
+		}
+		a, _ = splitRank(a, size(a)-size(b), cow, counter)
+		return a
+	})
+}
+
+// Union moves the values of a and b to a new tree holding the values of
+// either in O(m log(n/m+1)), where m <= n are the sizes of the trees. A value
+// is kept as many times as in the tree holding it the most. The new tree is
+// ordered like a, and a and b are left empty.
+func Union[T any](a, b *AVLTree[T]) *AVLTree[T] {
+	cow := joinCOW(a.cow, b.cow)
+	result := &AVLTree[T]{root: union(a.root, b.root, a.cmp, cow, a.counter), cmp: a.cmp, options: a.options, cow: cow, counter: a.counter}
+	a.root = nil
+	b.root = nil
+	return result
+}
+
+// Intersection moves the values of a and b to a new tree holding the values
+// of both in O(m log(n/m+1)), see Union. A value is kept as many times as in
+// the tree holding it the least.
+func Intersection[T any](a, b *AVLTree[T]) *AVLTree[T] {
+	cow := joinCOW(a.cow, b.cow)
+	result := &AVLTree[T]{root: intersection(a.root, b.root, a.cmp, cow, a.counter), cmp: a.cmp, options: a.options, cow: cow, counter: a.counter}
+	a.root = nil
+	b.root = nil
+	return result
+}
+
+// Difference moves the values of a and b to a new tree holding the values of
+// a that are not in b in O(m log(n/m+1)), see Union. A value is kept as many
+// times as it is in a more than in b.
+func Difference[T any](a, b *AVLTree[T]) *AVLTree[T] {
+	cow := joinCOW(a.cow, b.cow)
+	result := &AVLTree[T]{root: difference(a.root, b.root, a.cmp, cow, a.counter), cmp: a.cmp, options: a.options, cow: cow, counter: a.counter}
+	a.root = nil
+	b.root = nil
+	return result
+}
@@ -0,0 +1,111 @@
+package avl
+
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
+
+func height[T any](root *avlTreeNode[T]) int {
+	if root == nil {
+		return -1
+	}
+	return root.height
+}
+
+func size[T any](root *avlTreeNode[T]) uint {
+	if root == nil {
+		return 0
+	}
+	return root.size
+}
+
+// Join left, the single node middle and right, where left <= middle <= right
+func join3[T any](left, middle, right *avlTreeNode[T], cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
+	if height(left) > height(right)+1 {
+		left = left.mutable(cow)
+		left.right = join3(left.right, middle, right, cow, counter)
+		left.update()
+		return balance(left, cow, counter)
+	}
+	if height(right) > height(left)+1 {
+		right = right.mutable(cow)
+		right.left = join3(left, middle, right.left, cow, counter)
+		right.update()
+		return balance(right, cow, counter)
+	}
+	middle = middle.mutable(cow)
+	middle.left = left
+	middle.right = right
+	middle.update()
+	return middle
+}
+
+// Join left and right, where left <= right
+func join2[T any](left, right *avlTreeNode[T], cow *copyOnWrite, counter *stats.Counter) *avlTreeNode[T] {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	right, middle := deleteMin(right, cow, counter)
+	return join3(left, middle, right, cow, counter)
+}
+
+// Split root into the values for which left returns true and the others,
+// where left returns true for a prefix of the values
+func splitBy[T any](root *avlTreeNode[T], left func(value T) bool, cow *copyOnWrite, counter *stats.Counter) (*avlTreeNode[T], *avlTreeNode[T]) {
+	if root == nil {
+		return nil, nil
+	}
+	rootLeft, rootRight := root.left, root.right
+	if left(root.value) {
+		rightLeft, rightRight := splitBy(rootRight, left, cow, counter)
+		return join3(rootLeft, root, rightLeft, cow, counter), rightRight
+	} else {
+		leftLeft, leftRight := splitBy(rootLeft, left, cow, counter)
+		return leftLeft, join3(leftRight, root, rootRight, cow, counter)
+	}
+}
+
+// Split root into the k smallest values and the others
+func splitRank[T any](root *avlTreeNode[T], k uint, cow *copyOnWrite, counter *stats.Counter) (*avlTreeNode[T], *avlTreeNode[T]) {
+	if root == nil {
+		return nil, nil
+	}
+	left, right := root.left, root.right
+	if leftSize := size(left); k <= leftSize {
+		leftLeft, leftRight := splitRank(left, k, cow, counter)
+		return leftLeft, join3(leftRight, root, right, cow, counter)
+	} else {
+		rightLeft, rightRight := splitRank(right, k-leftSize-1, cow, counter)
+		return join3(left, root, rightLeft, cow, counter), rightRight
+	}
+}
+
+// SplitAt moves the values less than value to left and the others to right
+// in O(log n). t is left empty.
+func (t *AVLTree[T]) SplitAt(value T) (left, right *AVLTree[T]) {
+	l, r := splitBy(t.root, func(v T) bool {
+		return t.cmp(v, value) < 0
+	}, t.cow, t.counter)
+	t.root = nil
+	return &AVLTree[T]{root: l, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}, &AVLTree[T]{root: r, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// O(log n). t is left empty.
+func (t *AVLTree[T]) SplitRank(k uint) (left, right *AVLTree[T]) {
+	l, r := splitRank(t.root, k, t.cow, t.counter)
+	t.root = nil
+	return &AVLTree[T]{root: l, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}, &AVLTree[T]{root: r, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}
+}
+
+// Join moves the values of left and right to a new tree in O(log n). Every
+// value of left must be less than every value of right, or equal to it if
+// the trees are not sets, which is not checked. The new tree is ordered like
+// left, and left and right are left empty.
+func Join[T any](left, right *AVLTree[T]) *AVLTree[T] {
+	cow := joinCOW(left.cow, right.cow)
+	result := &AVLTree[T]{root: join2(left.root, right.root, cow, left.counter), cmp: left.cmp, options: left.options, cow: cow, counter: left.counter}
+	left.root = nil
+	right.root = nil
+	return result
+}
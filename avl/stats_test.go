@@ -0,0 +1,40 @@
+//go:build bstrees_stats
+
+package avl_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/avl"
+)
+
+func TestStats(t *testing.T) {
+	tree := avl.New[int]()
+	other := avl.New[int]()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		tree.Insert(r.Intn(500))
+		tree.Delete(r.Intn(500))
+	}
+	if stats := other.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() of another tree = %+v, want zero", stats)
+	}
+	stats := tree.Stats()
+	for _, c := range []struct {
+		name  string
+		count uint64
+	}{
+		{"Comparisons", stats.Comparisons},
+		{"Rotations", stats.Rotations},
+	} {
+		if c.count == 0 {
+			t.Errorf("Stats().%s = 0, want > 0", c.name)
+		}
+	}
+	tree.ResetStats()
+	if stats := tree.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() = %+v after ResetStats, want zero", stats)
+	}
+}
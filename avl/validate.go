@@ -0,0 +1,40 @@
+package avl
+
+import "github.com/yanglinshu/bstrees/v2/internal/invariant"
+
+func validate[T any](root *avlTreeNode[T], order *invariant.Order[T]) error {
+	if root == nil {
+		return nil
+	}
+	if err := validate(root.left, order); err != nil {
+		return err
+	}
+	if err := order.Next(root.value); err != nil {
+		return err
+	}
+	if err := validate(root.right, order); err != nil {
+		return err
+	}
+	if want := size(root.left) + size(root.right) + 1; root.size != want {
+		return invariant.Errorf(root.value, "size is %d, want %d", root.size, want)
+	}
+	left, right := height(root.left), height(root.right)
+	want := left + 1
+	if right > left {
+		want = right + 1
+	}
+	if root.height != want {
+		return invariant.Errorf(root.value, "height is %d, want %d", root.height, want)
+	}
+	if left-right > 1 || right-left > 1 {
+		return invariant.Errorf(root.value, "balance factor is %d", left-right)
+	}
+	return nil
+}
+
+// Validate checks the order of the values and, for every node, its cached
+// size and height and its balance factor. Returns a *bstrees.InvariantError
+// naming the first node found to break a rule.
+func (t *AVLTree[T]) Validate() error {
+	return validate(t.root, invariant.NewOrder(t.cmp, t.unique))
+}
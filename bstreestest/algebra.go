@@ -0,0 +1,71 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunSetAlgebraSuite checks the Union, Intersection and Difference functions
+// of the trees created by factory against sorted slices, with duplicates
+// counted as in multisets.
+func RunSetAlgebraSuite[Tree bstrees.Tree[int]](t *testing.T, factory func() Tree, union, intersection, difference func(a, b Tree) Tree) {
+	r := rand.New(rand.NewSource(1))
+	operations := []struct {
+		name  string
+		apply func(a, b Tree) Tree
+		count func(x, y int) int
+	}{
+		{"Union", union, func(x, y int) int {
+			if x > y {
+				return x
+			}
+			return y
+		}},
+		{"Intersection", intersection, func(x, y int) int {
+			if x < y {
+				return x
+			}
+			return y
+		}},
+		{"Difference", difference, func(x, y int) int {
+			if x > y {
+				return x - y
+			}
+			return 0
+		}},
+	}
+	sizes := [][2]int{{0, 0}, {0, 50}, {50, 0}, {1, 300}, {300, 1}, {20, 500}, {500, 20}, {200, 200}}
+	for _, operation := range operations {
+		for _, size := range sizes {
+			for _, limit := range []int{10, 1000} {
+				a, aCounts := randomTree(factory, r, size[0], limit)
+				b, bCounts := randomTree(factory, r, size[1], limit)
+				m := &model{}
+				for value := 0; value < limit; value++ {
+					for i := operation.count(aCounts[value], bCounts[value]); i > 0; i-- {
+						m.values = append(m.values, value)
+					}
+				}
+				tree := operation.apply(a, b)
+				if !a.Empty() || !b.Empty() {
+					t.Fatalf("%s left %d and %d values", operation.name, a.Size(), b.Size())
+				}
+				check(t, tree, m, -1, limit)
+				testMutateBuilt(t, tree, m, r, limit)
+			}
+		}
+	}
+}
+
+func randomTree[Tree bstrees.Tree[int]](factory func() Tree, r *rand.Rand, n, limit int) (Tree, map[int]int) {
+	tree, counts := factory(), map[int]int{}
+	for i := 0; i < n; i++ {
+		value := r.Intn(limit)
+		if tree.Insert(value) {
+			counts[value]++
+		}
+	}
+	return tree, counts
+}
@@ -0,0 +1,27 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunBenchmark inserts 10000 values in random order into a tree created by
+// factory, then looks each of them up and deletes them.
+func RunBenchmark(b *testing.B, factory func() bstrees.Tree[int]) {
+	values := rand.New(rand.NewSource(1)).Perm(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := factory()
+		for _, value := range values {
+			tree.Insert(value)
+		}
+		for _, value := range values {
+			tree.Contains(value)
+		}
+		for _, value := range values {
+			tree.Delete(value)
+		}
+	}
+}
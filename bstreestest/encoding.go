@@ -0,0 +1,118 @@
+package bstreestest
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// EncodingTree is a tree that can be marshaled in binary, JSON and gob.
+type EncodingTree interface {
+	bstrees.Tree[int]
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	json.Marshaler
+	json.Unmarshaler
+	gob.GobEncoder
+	gob.GobDecoder
+}
+
+var codecs = []struct {
+	name      string
+	marshal   func(tree EncodingTree) ([]byte, error)
+	unmarshal func(data []byte, tree EncodingTree) error
+}{
+	{"Binary", func(tree EncodingTree) ([]byte, error) { return tree.MarshalBinary() },
+		func(data []byte, tree EncodingTree) error { return tree.UnmarshalBinary(data) }},
+	{"JSON", func(tree EncodingTree) ([]byte, error) { return json.Marshal(tree) },
+		func(data []byte, tree EncodingTree) error { return json.Unmarshal(data, tree) }},
+	{"Gob", func(tree EncodingTree) ([]byte, error) {
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(tree)
+		return buf.Bytes(), err
+	}, func(data []byte, tree EncodingTree) error { return gob.NewDecoder(bytes.NewReader(data)).Decode(tree) }},
+}
+
+// RunEncodingSuite checks that the trees created by factory and shape are
+// restored from their encodings. The trees created by shape must preserve
+// their shape, which is checked by encoding the restored trees again.
+func RunEncodingSuite[Tree EncodingTree](t *testing.T, factory, shape func() Tree) {
+	for _, c := range codecs {
+		t.Run(c.name, func(t *testing.T) {
+			for _, preserve := range []bool{false, true} {
+				build := factory
+				if preserve {
+					build = shape
+				}
+				for _, n := range []int{0, 1, 2, 10, 500} {
+					r := rand.New(rand.NewSource(int64(n)))
+					tree, m := build(), &model{}
+					for i := 0; i < n; i++ {
+						value := r.Intn(n)
+						if r.Intn(4) == 0 {
+							tree.Delete(value)
+							m.delete(value)
+						} else if tree.Insert(value) {
+							m.insert(value)
+						}
+					}
+					data, err := c.marshal(tree)
+					if err != nil {
+						t.Fatalf("marshal: %v", err)
+					}
+					restored := build()
+					restored.Insert(n + 1) // Replaced by the decoded values
+					if err := c.unmarshal(data, restored); err != nil {
+						t.Fatalf("unmarshal: %v", err)
+					}
+					if preserve {
+						again, err := c.marshal(restored)
+						if err != nil {
+							t.Fatalf("marshal: %v", err)
+						}
+						if !bytes.Equal(data, again) {
+							t.Errorf("shape of %d values is not preserved", n)
+						}
+					}
+					check(t, restored, m, -1, n+1)
+					testMutateBuilt(t, restored, m, r, n+1)
+
+					// Either encoding can be decoded by any tree
+					other := factory()
+					if !preserve {
+						other = shape()
+					}
+					if err := c.unmarshal(data, other); err != nil {
+						t.Fatalf("unmarshal: %v", err)
+					}
+					if size := other.Size(); size != tree.Size() {
+						t.Errorf("Size() = %d, want %d", size, tree.Size())
+					}
+				}
+			}
+		})
+	}
+	t.Run("Corrupted", func(t *testing.T) {
+		for _, data := range []string{
+			`{"values":[2,1]}`,
+			`{"nodes":[{"value":2,"left":true}]}`,
+			`{"nodes":[{"value":2,"left":true,"count":1,"level":1},{"value":3,"count":1,"level":1}]}`,
+			`{"nodes":[{"value":2,"count":1,"level":1},{"value":3,"count":1,"level":1}]}`,
+		} {
+			tree := factory()
+			tree.Insert(1)
+			if err := json.Unmarshal([]byte(data), tree); !errors.Is(err, bstrees.ErrDataIsCorrupted) {
+				t.Errorf("Unmarshal(%s) = %v, want %v", data, err, bstrees.ErrDataIsCorrupted)
+			}
+			if !tree.Contains(1) || tree.Size() != 1 {
+				t.Errorf("Unmarshal(%s) has modified the tree", data)
+			}
+		}
+	})
+}
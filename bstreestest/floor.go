@@ -0,0 +1,52 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+type floorCeilingTree interface {
+	bstrees.Tree[int]
+	Floor(value int) (int, bool)
+	Ceiling(value int) (int, bool)
+}
+
+func testFloorCeiling(t *testing.T, tree bstrees.Tree[int]) {
+	fc, ok := tree.(floorCeilingTree)
+	if !ok {
+		t.Skip("Floor and Ceiling are not implemented")
+	}
+	r := rand.New(rand.NewSource(1))
+	m := &model{}
+	checkFloorCeiling(t, fc, m)
+	for i := 0; i < 500; i++ {
+		value := r.Intn(100) * 2
+		if r.Intn(3) == 0 {
+			fc.Delete(value)
+			m.delete(value)
+		} else {
+			fc.Insert(value)
+			m.insert(value)
+		}
+		if i%50 == 0 {
+			checkFloorCeiling(t, fc, m)
+		}
+	}
+	checkFloorCeiling(t, fc, m)
+}
+
+func checkFloorCeiling(t *testing.T, tree floorCeilingTree, m *model) {
+	t.Helper()
+	for value := -2; value <= 202; value++ {
+		want, wantOk := m.floor(value)
+		if got, ok := tree.Floor(value); ok != wantOk || (ok && got != want) {
+			t.Fatalf("Floor(%d) = %d, %v, want %d, %v", value, got, ok, want, wantOk)
+		}
+		want, wantOk = m.ceiling(value)
+		if got, ok := tree.Ceiling(value); ok != wantOk || (ok && got != want) {
+			t.Fatalf("Ceiling(%d) = %d, %v, want %d, %v", value, got, ok, want, wantOk)
+		}
+	}
+}
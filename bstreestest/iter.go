@@ -0,0 +1,61 @@
+//go:build go1.23
+
+package bstreestest
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+type iteratorTree interface {
+	bstrees.Tree[int]
+	All() iter.Seq[int]
+	Backward() iter.Seq[int]
+	Range(greaterOrEqual, lessThan int) iter.Seq[int]
+	RangeBackward(lessOrEqual, greaterThan int) iter.Seq[int]
+}
+
+func testIterator(t *testing.T, tree bstrees.Tree[int]) {
+	it, ok := tree.(iteratorTree)
+	if !ok {
+		t.Skip("All, Backward, Range and RangeBackward are not implemented")
+	}
+	m := &model{}
+	for _, value := range []int{4, 1, 3, 3, 5, 9, 2} {
+		it.Insert(value)
+		m.insert(value)
+	}
+	got := []int{}
+	for value := range it.All() {
+		got = append(got, value)
+	}
+	if !equal(got, m.values) {
+		t.Errorf("All() = %v, want %v", got, m.values)
+	}
+	got = []int{}
+	for value := range it.Backward() {
+		if value < 3 {
+			break
+		}
+		got = append(got, value)
+	}
+	if want := []int{9, 5, 4, 3, 3}; !equal(got, want) {
+		t.Errorf("Backward() until 3 = %v, want %v", got, want)
+	}
+	got = []int{}
+	for value := range it.Range(2, 5) {
+		got = append(got, value)
+	}
+	if want := []int{2, 3, 3, 4}; !equal(got, want) {
+		t.Errorf("Range(2, 5) = %v, want %v", got, want)
+	}
+	got = []int{}
+	for value := range it.RangeBackward(5, 2) {
+		got = append(got, value)
+	}
+	if want := []int{5, 4, 3, 3}; !equal(got, want) {
+		t.Errorf("RangeBackward(5, 2) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !go1.23
+
+package bstreestest
+
+import (
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+func testIterator(t *testing.T, tree bstrees.Tree[int]) {
+	t.Skip("range-over-func iterators require Go 1.23")
+}
@@ -0,0 +1,114 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+type iterableTree interface {
+	bstrees.Tree[int]
+	Ascend(fn func(value int) bool)
+	Descend(fn func(value int) bool)
+	AscendRange(greaterOrEqual, lessThan int, fn func(value int) bool)
+	DescendRange(lessOrEqual, greaterThan int, fn func(value int) bool)
+}
+
+func testIterate(t *testing.T, tree bstrees.Tree[int]) {
+	it, ok := tree.(iterableTree)
+	if !ok {
+		t.Skip("Ascend, Descend, AscendRange and DescendRange are not implemented")
+	}
+	r := rand.New(rand.NewSource(1))
+	m := &model{}
+	checkIterate(t, it, m)
+	for i := 0; i < 300; i++ {
+		value := r.Intn(50)
+		it.Insert(value)
+		m.insert(value)
+	}
+	checkIterate(t, it, m)
+	for i := 0; i < 200; i++ {
+		value := r.Intn(50)
+		it.Delete(value)
+		m.delete(value)
+	}
+	checkIterate(t, it, m)
+	it.Clear()
+	checkIterate(t, it, &model{})
+}
+
+// collect calls iterate with a function recording the values, which stops
+// after limit values.
+func collect(iterate func(fn func(int) bool), limit int) []int {
+	result := []int{}
+	iterate(func(value int) bool {
+		result = append(result, value)
+		return len(result) < limit
+	})
+	return result
+}
+
+func reversed(values []int) []int {
+	result := make([]int, len(values))
+	for i, value := range values {
+		result[len(values)-1-i] = value
+	}
+	return result
+}
+
+func checkIterate(t *testing.T, tree iterableTree, m *model) {
+	t.Helper()
+	if m.values == nil {
+		m.values = []int{}
+	}
+	for _, limit := range []int{len(m.values) + 1, len(m.values)/2 + 1, 1} {
+		want := m.values
+		if len(want) > limit {
+			want = want[:limit]
+		}
+		if got := collect(tree.Ascend, limit); !equal(got, want) {
+			t.Fatalf("Ascend stopping after %d values = %v, want %v", limit, got, want)
+		}
+		want = reversed(m.values)
+		if len(want) > limit {
+			want = want[:limit]
+		}
+		if got := collect(tree.Descend, limit); !equal(got, want) {
+			t.Fatalf("Descend stopping after %d values = %v, want %v", limit, got, want)
+		}
+	}
+	for lo := -1; lo <= 51; lo += 3 {
+		for hi := lo - 1; hi <= 52; hi += 5 {
+			want := m.between(m.lowerBound(lo), m.lowerBound(hi))
+			got := collect(func(fn func(int) bool) { tree.AscendRange(lo, hi, fn) }, len(m.values)+1)
+			if !equal(got, want) {
+				t.Fatalf("AscendRange(%d, %d) = %v, want %v", lo, hi, got, want)
+			}
+			want = reversed(m.between(m.upperBound(lo), m.upperBound(hi)))
+			got = collect(func(fn func(int) bool) { tree.DescendRange(hi, lo, fn) }, len(m.values)+1)
+			if !equal(got, want) {
+				t.Fatalf("DescendRange(%d, %d) = %v, want %v", hi, lo, got, want)
+			}
+		}
+	}
+	if len(m.values) > 2 {
+		lo, hi := m.values[0], m.values[len(m.values)-1]+1
+		if got := collect(func(fn func(int) bool) { tree.AscendRange(lo, hi, fn) }, 2); !equal(got, m.values[:2]) {
+			t.Fatalf("AscendRange(%d, %d) stopping after 2 values = %v, want %v", lo, hi, got, m.values[:2])
+		}
+	}
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package bstreestest
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunMapSuite checks the maps created by factory against a sorted slice of
+// keys and a Go map. Each subtest calls factory to get a fresh, empty map.
+func RunMapSuite(t *testing.T, factory func() bstrees.Map[int, int]) {
+	t.Run("Empty", func(t *testing.T) { testMapEmpty(t, factory()) })
+	t.Run("Operations", func(t *testing.T) { testMapOperations(t, factory()) })
+	t.Run("Random", func(t *testing.T) { testMapRandom(t, factory()) })
+}
+
+func testMapEmpty(t *testing.T, m bstrees.Map[int, int]) {
+	if !m.Empty() || m.Size() != 0 {
+		t.Errorf("Empty(), Size() = %v, %d on a new map", m.Empty(), m.Size())
+	}
+	if value, ok := m.Get(1); ok {
+		t.Errorf("Get(1) = %d, true on a new map", value)
+	}
+	if m.Delete(1) {
+		t.Error("Delete(1) = true on a new map")
+	}
+	if _, _, err := m.At(1); !errors.Is(err, bstrees.ErrIndexIsOutOfRange) {
+		t.Errorf("At(1) error = %v on a new map, want %v", err, bstrees.ErrIndexIsOutOfRange)
+	}
+	if _, _, err := m.Predecessor(1); !errors.Is(err, bstrees.ErrPredecessorDoesNotExist) {
+		t.Errorf("Predecessor(1) error = %v on a new map, want %v", err, bstrees.ErrPredecessorDoesNotExist)
+	}
+	if _, _, err := m.Successor(1); !errors.Is(err, bstrees.ErrSuccessorDoesNotExist) {
+		t.Errorf("Successor(1) error = %v on a new map, want %v", err, bstrees.ErrSuccessorDoesNotExist)
+	}
+}
+
+func testMapOperations(t *testing.T, m bstrees.Map[int, int]) {
+	m.Put(2, 20)
+	m.Put(1, 10)
+	m.Put(2, 21) // Overwrites
+	if value, ok := m.Get(2); !ok || value != 21 {
+		t.Errorf("Get(2) = %d, %v, want 21, true", value, ok)
+	}
+	if size := m.Size(); size != 2 {
+		t.Errorf("Size() = %d, want 2", size)
+	}
+	if value, loaded := m.GetOrInsert(1, 11); !loaded || value != 10 {
+		t.Errorf("GetOrInsert(1, 11) = %d, %v, want 10, true", value, loaded)
+	}
+	if value, loaded := m.GetOrInsert(3, 30); loaded || value != 30 {
+		t.Errorf("GetOrInsert(3, 30) = %d, %v, want 30, false", value, loaded)
+	}
+	increment := func(value int, ok bool) int {
+		if !ok {
+			return -1
+		}
+		return value + 1
+	}
+	m.Update(3, increment)
+	m.Update(4, increment)
+	if value, _ := m.Get(3); value != 31 {
+		t.Errorf("Get(3) = %d after Update, want 31", value)
+	}
+	if value, _ := m.Get(4); value != -1 {
+		t.Errorf("Get(4) = %d after Update, want -1", value)
+	}
+	if key, value, err := m.At(2); err != nil || key != 2 || value != 21 {
+		t.Errorf("At(2) = %d, %d, %v, want 2, 21, nil", key, value, err)
+	}
+	if key, value, err := m.Predecessor(3); err != nil || key != 2 || value != 21 {
+		t.Errorf("Predecessor(3) = %d, %d, %v, want 2, 21, nil", key, value, err)
+	}
+	if key, value, err := m.Successor(3); err != nil || key != 4 || value != -1 {
+		t.Errorf("Successor(3) = %d, %d, %v, want 4, -1, nil", key, value, err)
+	}
+	if !m.Delete(2) || m.Delete(2) {
+		t.Error("Delete(2) twice did not return true then false")
+	}
+	if index := m.Index(3); index != 2 {
+		t.Errorf("Index(3) = %d, want 2", index)
+	}
+	m.Update(2, func(value int, ok bool) int {
+		if ok || value != 0 {
+			t.Errorf("Update(2) got %d, %v after Delete(2), want 0, false", value, ok)
+		}
+		return value
+	})
+	m.Clear()
+	if !m.Empty() || m.Contains(1) {
+		t.Error("Clear() left values in the map")
+	}
+}
+
+func testMapRandom(t *testing.T, m bstrees.Map[int, int]) {
+	r := rand.New(rand.NewSource(1))
+	keys := &model{}
+	values := map[int]int{}
+	for i := 0; i < 2000; i++ {
+		key := r.Intn(200)
+		_, present := values[key]
+		if r.Intn(3) == 0 {
+			if deleted := m.Delete(key); deleted != present {
+				t.Fatalf("Delete(%d) = %v, want %v", key, deleted, present)
+			}
+			keys.delete(key)
+			delete(values, key)
+		} else {
+			m.Put(key, i)
+			if !present {
+				keys.insert(key)
+			}
+			values[key] = i
+		}
+	}
+	if size := m.Size(); size != uint(len(keys.values)) {
+		t.Fatalf("Size() = %d, want %d", size, len(keys.values))
+	}
+	for i, wantKey := range keys.values {
+		key, value, err := m.At(uint(i + 1))
+		if err != nil || key != wantKey || value != values[wantKey] {
+			t.Fatalf("At(%d) = %d, %d, %v, want %d, %d, nil", i+1, key, value, err, wantKey, values[wantKey])
+		}
+	}
+	if it, ok := m.(interface {
+		Ascend(fn func(key, value int) bool)
+		DescendRange(lessOrEqual, greaterThan int, fn func(key, value int) bool)
+	}); ok {
+		got := []int{}
+		it.Ascend(func(key, value int) bool {
+			if value != values[key] {
+				t.Fatalf("Ascend visited %d, %d, want %d, %d", key, value, key, values[key])
+			}
+			got = append(got, key)
+			return true
+		})
+		if !equal(got, keys.values) {
+			t.Fatalf("Ascend visited keys %v, want %v", got, keys.values)
+		}
+		got = []int{}
+		it.DescendRange(150, 50, func(key, value int) bool {
+			got = append(got, key)
+			return true
+		})
+		if want := reversed(keys.between(keys.upperBound(50), keys.upperBound(150))); !equal(got, want) {
+			t.Fatalf("DescendRange(150, 50) visited keys %v, want %v", got, want)
+		}
+	}
+	for key := -1; key <= 201; key++ {
+		want, present := values[key]
+		if value, ok := m.Get(key); ok != present || value != want {
+			t.Fatalf("Get(%d) = %d, %v, want %d, %v", key, value, ok, want, present)
+		}
+		if got, want := m.Index(key), keys.index(key); got != want {
+			t.Fatalf("Index(%d) = %d, want %d", key, got, want)
+		}
+		if want, ok := keys.predecessor(key); ok {
+			if got, value, err := m.Predecessor(key); err != nil || got != want || value != values[want] {
+				t.Fatalf("Predecessor(%d) = %d, %d, %v, want %d, %d, nil", key, got, value, err, want, values[want])
+			}
+		}
+		if want, ok := keys.successor(key); ok {
+			if got, value, err := m.Successor(key); err != nil || got != want || value != values[want] {
+				t.Fatalf("Successor(%d) = %d, %d, %v, want %d, %d, nil", key, got, value, err, want, values[want])
+			}
+		}
+	}
+}
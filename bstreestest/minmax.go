@@ -0,0 +1,75 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+type minMaxTree interface {
+	bstrees.Tree[int]
+	Min() (int, bool)
+	Max() (int, bool)
+	PopMin() (int, bool)
+	PopMax() (int, bool)
+}
+
+func testMinMax(t *testing.T, tree bstrees.Tree[int]) {
+	mm, ok := tree.(minMaxTree)
+	if !ok {
+		t.Skip("Min, Max, PopMin and PopMax are not implemented")
+	}
+	for name, fn := range map[string]func() (int, bool){
+		"Min": mm.Min, "Max": mm.Max, "PopMin": mm.PopMin, "PopMax": mm.PopMax,
+	} {
+		if value, ok := fn(); ok {
+			t.Errorf("%s() = %d, true on a new tree", name, value)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	m := &model{}
+	for i := 0; i < 300; i++ {
+		value := r.Intn(100)
+		mm.Insert(value)
+		m.insert(value)
+	}
+	for len(m.values) > 0 {
+		checkMinMax(t, mm, m)
+		var got, want int
+		var ok bool
+		switch r.Intn(3) {
+		case 0:
+			got, ok = mm.PopMin()
+			want = m.values[0]
+			m.values = m.values[1:]
+		case 1:
+			got, ok = mm.PopMax()
+			want = m.values[len(m.values)-1]
+			m.values = m.values[:len(m.values)-1]
+		default:
+			value := r.Intn(100)
+			mm.Insert(value)
+			m.insert(value)
+			continue
+		}
+		if !ok || got != want {
+			t.Fatalf("Pop = %d, %v, want %d, true", got, ok, want)
+		}
+		if size := mm.Size(); size != uint(len(m.values)) {
+			t.Fatalf("Size() = %d after Pop, want %d", size, len(m.values))
+		}
+	}
+	check(t, mm, m, -1, 101)
+}
+
+func checkMinMax(t *testing.T, tree minMaxTree, m *model) {
+	t.Helper()
+	if got, ok := tree.Min(); !ok || got != m.values[0] {
+		t.Fatalf("Min() = %d, %v, want %d, true", got, ok, m.values[0])
+	}
+	if got, ok := tree.Max(); !ok || got != m.values[len(m.values)-1] {
+		t.Fatalf("Max() = %d, %v, want %d, true", got, ok, m.values[len(m.values)-1])
+	}
+}
@@ -0,0 +1,80 @@
+package bstreestest
+
+import "sort"
+
+// model is a sorted slice that behaves like a Tree, used as the reference
+// implementation.
+type model struct {
+	values []int
+}
+
+func (m *model) lowerBound(value int) int {
+	return sort.SearchInts(m.values, value)
+}
+
+func (m *model) upperBound(value int) int {
+	return sort.Search(len(m.values), func(i int) bool { return m.values[i] > value })
+}
+
+func (m *model) insert(value int) {
+	i := m.upperBound(value)
+	m.values = append(m.values, 0)
+	copy(m.values[i+1:], m.values[i:])
+	m.values[i] = value
+}
+
+func (m *model) delete(value int) {
+	i := m.lowerBound(value)
+	if i < len(m.values) && m.values[i] == value {
+		m.values = append(m.values[:i], m.values[i+1:]...)
+	}
+}
+
+func (m *model) contains(value int) bool {
+	i := m.lowerBound(value)
+	return i < len(m.values) && m.values[i] == value
+}
+
+func (m *model) index(value int) uint {
+	return uint(m.lowerBound(value)) + 1
+}
+
+func (m *model) predecessor(value int) (int, bool) {
+	i := m.lowerBound(value)
+	if i == 0 {
+		return 0, false
+	}
+	return m.values[i-1], true
+}
+
+func (m *model) successor(value int) (int, bool) {
+	i := m.upperBound(value)
+	if i == len(m.values) {
+		return 0, false
+	}
+	return m.values[i], true
+}
+
+// between returns the values with an index in [i, j), or none if j < i.
+func (m *model) between(i, j int) []int {
+	if j < i {
+		return []int{}
+	}
+	return m.values[i:j]
+}
+
+func (m *model) floor(value int) (int, bool) {
+	i := m.upperBound(value)
+	if i == 0 {
+		return 0, false
+	}
+	return m.values[i-1], true
+}
+
+func (m *model) ceiling(value int) (int, bool) {
+	i := m.lowerBound(value)
+	if i == len(m.values) {
+		return 0, false
+	}
+	return m.values[i], true
+}
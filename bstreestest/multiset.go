@@ -0,0 +1,55 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+type multisetTree interface {
+	bstrees.Tree[int]
+	Count(value int) uint
+	DeleteAll(value int) uint
+	EqualRange(value int) (first, last uint)
+}
+
+func testMultiset(t *testing.T, tree bstrees.Tree[int]) {
+	ms, ok := tree.(multisetTree)
+	if !ok {
+		t.Skip("Count, DeleteAll and EqualRange are not implemented")
+	}
+	if count := ms.DeleteAll(1); count != 0 {
+		t.Errorf("DeleteAll(1) = %d on a new tree", count)
+	}
+	r := rand.New(rand.NewSource(1))
+	m := &model{}
+	for i := 0; i < 500; i++ {
+		value := r.Intn(30)
+		ms.Insert(value)
+		m.insert(value)
+	}
+	for len(m.values) > 0 {
+		checkMultiset(t, ms, m)
+		value := r.Intn(32)
+		want := uint(m.upperBound(value) - m.lowerBound(value))
+		if count := ms.DeleteAll(value); count != want {
+			t.Fatalf("DeleteAll(%d) = %d, want %d", value, count, want)
+		}
+		m.values = append(m.values[:m.lowerBound(value)], m.values[m.upperBound(value):]...)
+		check(t, ms, m, -1, 32)
+	}
+}
+
+func checkMultiset(t *testing.T, tree multisetTree, m *model) {
+	t.Helper()
+	for value := -1; value <= 32; value++ {
+		wantFirst, wantLast := uint(m.lowerBound(value))+1, uint(m.upperBound(value))+1
+		if first, last := tree.EqualRange(value); first != wantFirst || last != wantLast {
+			t.Fatalf("EqualRange(%d) = %d, %d, want %d, %d", value, first, last, wantFirst, wantLast)
+		}
+		if count := tree.Count(value); count != wantLast-wantFirst {
+			t.Fatalf("Count(%d) = %d, want %d", value, count, wantLast-wantFirst)
+		}
+	}
+}
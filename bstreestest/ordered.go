@@ -0,0 +1,67 @@
+package bstreestest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+type countTree[T any] interface {
+	Count(value T) uint
+}
+
+// RunOrderedSuite checks the trees of strings created by strings, and the
+// trees built by unsigned from sorted unsigned integers, for the trees that
+// accept any ordered type.
+func RunOrderedSuite(t *testing.T, strings func() bstrees.Tree[string], unsigned func(values []uint) bstrees.Tree[uint]) {
+	t.Run("Strings", func(t *testing.T) { testStrings(t, strings()) })
+	t.Run("Unsigned", func(t *testing.T) { testUnsigned(t, unsigned([]uint{0, 0, 1, 2})) })
+}
+
+func testStrings(t *testing.T, tree bstrees.Tree[string]) {
+	for _, value := range []string{"pear", "apple", "fig", "apple", "kiwi"} {
+		tree.Insert(value)
+	}
+	tree.Delete("fig")
+	if got := tree.Index("kiwi"); got != 3 {
+		t.Errorf("Index(kiwi) = %d, want 3", got)
+	}
+	if got, err := tree.At(2); err != nil || got != "apple" {
+		t.Errorf("At(2) = %q, %v, want apple, nil", got, err)
+	}
+	if got, err := tree.Predecessor("b"); err != nil || got != "apple" {
+		t.Errorf("Predecessor(b) = %q, %v, want apple, nil", got, err)
+	}
+	if got, err := tree.Successor("kiwi"); err != nil || got != "pear" {
+		t.Errorf("Successor(kiwi) = %q, %v, want pear, nil", got, err)
+	}
+	if got, err := tree.Successor("apple"); err != nil || got != "kiwi" {
+		t.Errorf("Successor(apple) = %q, %v, want kiwi, nil", got, err)
+	}
+	tree.Delete("apple")
+	if ct, ok := tree.(countTree[string]); ok {
+		if got := ct.Count("apple"); got != 1 {
+			t.Errorf("Count(apple) = %d, want 1", got)
+		}
+	}
+}
+
+// tree holds 0, 0, 1 and 2
+func testUnsigned(t *testing.T, tree bstrees.Tree[uint]) {
+	if got := tree.Index(0); got != 1 {
+		t.Errorf("Index(0) = %d, want 1", got)
+	}
+	if _, err := tree.Predecessor(0); !errors.Is(err, bstrees.ErrPredecessorDoesNotExist) {
+		t.Errorf("Predecessor(0) error = %v, want %v", err, bstrees.ErrPredecessorDoesNotExist)
+	}
+	if got, err := tree.Successor(0); err != nil || got != 1 {
+		t.Errorf("Successor(0) = %d, %v, want 1, nil", got, err)
+	}
+	tree.Delete(0)
+	if ct, ok := tree.(countTree[uint]); ok {
+		if got := ct.Count(0); got != 1 {
+			t.Errorf("Count(0) = %d, want 1", got)
+		}
+	}
+}
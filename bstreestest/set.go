@@ -0,0 +1,56 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunSetSuite checks the trees created by factory, which must ignore values
+// already present, against a sorted slice without duplicates. Each subtest
+// calls factory to get a fresh, empty tree.
+func RunSetSuite(t *testing.T, factory func() bstrees.Tree[int]) {
+	t.Run("Empty", func(t *testing.T) { testEmpty(t, factory()) })
+	t.Run("Sequential", func(t *testing.T) { testSetSequential(t, factory()) })
+	t.Run("Random", func(t *testing.T) { testSetRandom(t, factory()) })
+}
+
+func testSetSequential(t *testing.T, tree bstrees.Tree[int]) {
+	m := &model{}
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 50; i++ {
+			if inserted := tree.Insert(i); inserted != (round == 0) {
+				t.Fatalf("Insert(%d) = %v in round %d", i, inserted, round)
+			}
+			if round == 0 {
+				m.insert(i)
+			}
+		}
+		check(t, tree, m, -1, 51)
+	}
+}
+
+func testSetRandom(t *testing.T, tree bstrees.Tree[int]) {
+	r := rand.New(rand.NewSource(1))
+	m := &model{}
+	for i := 0; i < 3000; i++ {
+		value := r.Intn(200)
+		if r.Intn(3) == 0 {
+			tree.Delete(value)
+			m.delete(value)
+		} else {
+			present := m.contains(value)
+			if inserted := tree.Insert(value); inserted == present {
+				t.Fatalf("Insert(%d) = %v, want %v", value, inserted, !present)
+			}
+			if !present {
+				m.insert(value)
+			}
+		}
+		if i%100 == 0 {
+			check(t, tree, m, -1, 201)
+		}
+	}
+	check(t, tree, m, -1, 201)
+}
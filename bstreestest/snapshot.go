@@ -0,0 +1,97 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunSnapshotSuite checks that the snapshots taken by snapshot keep their
+// values while the trees created by factory are modified, and the other way
+// around. splitAt and join are used to move shared nodes between trees.
+func RunSnapshotSuite[Tree bstrees.Tree[int]](t *testing.T, factory func() Tree, snapshot func(tree Tree) Tree, splitAt func(tree Tree, value int) (Tree, Tree), join func(left, right Tree) Tree) {
+	t.Run("Mutate", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		tree, m := factory(), &model{}
+		var snapshots []Tree
+		var models []*model
+		for i := 0; i < 2000; i++ {
+			if i%100 == 0 {
+				snapshots = append(snapshots, snapshot(tree))
+				models = append(models, &model{values: append([]int{}, m.values...)})
+			}
+			value := r.Intn(200)
+			if r.Intn(3) == 0 {
+				tree.Delete(value)
+				m.delete(value)
+			} else if tree.Insert(value) {
+				m.insert(value)
+			}
+			if i%10 == 0 && len(snapshots) > 0 {
+				// Modifying a snapshot must not modify the tree either
+				k := r.Intn(len(snapshots))
+				value := r.Intn(200)
+				if snapshots[k].Insert(value) {
+					models[k].insert(value)
+				}
+			}
+		}
+		check(t, tree, m, -1, 200)
+		for k := range snapshots {
+			check(t, snapshots[k], models[k], -1, 200)
+		}
+	})
+	t.Run("SplitJoin", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		tree, m := factory(), &model{}
+		for i := 0; i < 500; i++ {
+			value := r.Intn(200)
+			if tree.Insert(value) {
+				m.insert(value)
+			}
+		}
+		frozen := snapshot(tree)
+		left, right := splitAt(tree, 100)
+		rightModel := &model{values: append([]int{}, m.values[m.lowerBound(100):]...)}
+		// The snapshot of right shares nodes with right, so their join with
+		// left must copy them
+		joined := join(left, snapshot(right))
+		for i := 0; i < 500; i++ {
+			joined.Insert(r.Intn(200))
+			joined.Delete(r.Intn(200))
+		}
+		check(t, right, rightModel, -1, 200)
+		check(t, frozen, m, -1, 200)
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		tree, m := factory(), &model{}
+		for i := 0; i < 1000; i++ {
+			value := r.Intn(1000)
+			if tree.Insert(value) {
+				m.insert(value)
+			}
+		}
+		frozen := snapshot(tree)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				for k, want := range m.values {
+					if got, err := frozen.At(uint(k + 1)); err != nil || got != want {
+						t.Errorf("At(%d) = %d, %v, want %d, nil", k+1, got, err, want)
+						return
+					}
+				}
+			}
+		}()
+		for i := 0; i < 5000; i++ {
+			tree.Insert(r.Intn(1000))
+			tree.Delete(r.Intn(1000))
+		}
+		wg.Wait()
+	})
+}
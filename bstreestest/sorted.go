@@ -0,0 +1,45 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunFromSortedSuite checks the trees built by build from sorted values,
+// which may contain duplicates, against a sorted slice. The trees are then
+// modified to make sure they are usable.
+func RunFromSortedSuite(t *testing.T, build func(values []int) bstrees.Tree[int]) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n <= 70; n++ {
+		m := &model{}
+		for i := 0; i < n; i++ {
+			m.values = append(m.values, i*2)
+		}
+		tree := build(m.values)
+		check(t, tree, m, -1, 2*n+1)
+		testMutateBuilt(t, tree, m, r, 2*n)
+	}
+	m := &model{}
+	for i := 0; i < 1000; i++ {
+		m.insert(r.Intn(300))
+	}
+	tree := build(m.values)
+	check(t, tree, m, -1, 301)
+	testMutateBuilt(t, tree, m, r, 300)
+}
+
+func testMutateBuilt(t *testing.T, tree bstrees.Tree[int], m *model, r *rand.Rand, limit int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		value := r.Intn(limit + 1)
+		if r.Intn(2) == 0 {
+			tree.Delete(value)
+			m.delete(value)
+		} else if tree.Insert(value) {
+			m.insert(value)
+		}
+	}
+	check(t, tree, m, -1, limit+1)
+}
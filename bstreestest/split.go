@@ -0,0 +1,76 @@
+package bstreestest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunSplitJoinSuite checks splitAt, splitRank and join, the SplitAt and
+// SplitRank methods and the Join function of the trees created by factory,
+// against a sorted slice.
+func RunSplitJoinSuite[Tree bstrees.Tree[int]](t *testing.T, factory func() Tree, splitAt func(tree Tree, value int) (Tree, Tree), splitRank func(tree Tree, k uint) (Tree, Tree), join func(left, right Tree) Tree) {
+	r := rand.New(rand.NewSource(1))
+	build := func(values []int) (Tree, *model) {
+		tree, m := factory(), &model{}
+		for _, value := range values {
+			tree.Insert(value)
+			m.insert(value)
+		}
+		return tree, m
+	}
+	for _, n := range []int{0, 1, 2, 7, 64, 150} {
+		values := make([]int, n)
+		for i := range values {
+			values[i] = r.Intn(n + 1)
+		}
+		for k := 0; k <= n+1; k++ {
+			tree, m := build(values)
+			var left, right Tree
+			if r.Intn(2) == 0 {
+				left, right = splitAt(tree, k)
+				testSplit(t, tree, left, right, m, m.lowerBound(k), n)
+			} else {
+				left, right = splitRank(tree, uint(k))
+				rank := k
+				if rank > len(m.values) {
+					rank = len(m.values)
+				}
+				testSplit(t, tree, left, right, m, rank, n)
+			}
+			tree = join(left, right)
+			check(t, tree, m, -1, n+1)
+			testMutateBuilt(t, tree, m, r, n)
+		}
+	}
+	for _, sizes := range [][2]int{{0, 100}, {100, 0}, {1, 500}, {500, 1}, {37, 300}, {300, 37}, {256, 255}} {
+		var leftValues, rightValues []int
+		for i := 0; i < sizes[0]; i++ {
+			leftValues = append(leftValues, r.Intn(200))
+		}
+		for i := 0; i < sizes[1]; i++ {
+			rightValues = append(rightValues, 200+r.Intn(200))
+		}
+		left, m := build(leftValues)
+		right, _ := build(rightValues)
+		for _, value := range rightValues {
+			m.insert(value)
+		}
+		tree := join(left, right)
+		if !left.Empty() || !right.Empty() {
+			t.Fatalf("Join left %d and %d values", left.Size(), right.Size())
+		}
+		check(t, tree, m, -1, 401)
+		testMutateBuilt(t, tree, m, r, 400)
+	}
+}
+
+func testSplit[Tree bstrees.Tree[int]](t *testing.T, tree, left, right Tree, m *model, k, limit int) {
+	t.Helper()
+	if !tree.Empty() {
+		t.Fatalf("split left %d values", tree.Size())
+	}
+	check(t, left, &model{values: m.values[:k]}, -1, limit+1)
+	check(t, right, &model{values: m.values[k:]}, -1, limit+1)
+}
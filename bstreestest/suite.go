@@ -0,0 +1,186 @@
+// Package bstreestest provides a conformance suite for implementations of
+// bstrees.Tree. Every tree of this module is checked against it, and it can be
+// used the same way for trees living outside of the module:
+//
+//	func TestTree(t *testing.T) {
+//		bstreestest.RunSuite(t, func() bstrees.Tree[int] { return mytree.New[int]() })
+//	}
+package bstreestest
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// RunSuite checks the trees created by factory against a sorted slice. Each
+// subtest calls factory to get a fresh, empty tree.
+func RunSuite(t *testing.T, factory func() bstrees.Tree[int]) {
+	t.Run("Empty", func(t *testing.T) { testEmpty(t, factory()) })
+	t.Run("Sequential", func(t *testing.T) { testSequential(t, factory()) })
+	t.Run("Duplicates", func(t *testing.T) { testDuplicates(t, factory()) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, factory()) })
+	t.Run("Random", func(t *testing.T) { testRandom(t, factory()) })
+	t.Run("Iterate", func(t *testing.T) { testIterate(t, factory()) })
+	t.Run("Iterator", func(t *testing.T) { testIterator(t, factory()) })
+	t.Run("FloorCeiling", func(t *testing.T) { testFloorCeiling(t, factory()) })
+	t.Run("MinMax", func(t *testing.T) { testMinMax(t, factory()) })
+	t.Run("Multiset", func(t *testing.T) { testMultiset(t, factory()) })
+}
+
+func testEmpty(t *testing.T, tree bstrees.Tree[int]) {
+	if !tree.Empty() {
+		t.Error("Empty() = false on a new tree")
+	}
+	if size := tree.Size(); size != 0 {
+		t.Errorf("Size() = %d on a new tree", size)
+	}
+	if tree.Contains(0) {
+		t.Error("Contains(0) = true on a new tree")
+	}
+	if index := tree.Index(42); index != 1 {
+		t.Errorf("Index(42) = %d on a new tree, want 1", index)
+	}
+	for _, k := range []uint{0, 1, 2} {
+		if _, err := tree.At(k); !errors.Is(err, bstrees.ErrIndexIsOutOfRange) {
+			t.Errorf("At(%d) error = %v on a new tree, want %v", k, err, bstrees.ErrIndexIsOutOfRange)
+		}
+	}
+	if _, err := tree.Predecessor(42); !errors.Is(err, bstrees.ErrPredecessorDoesNotExist) {
+		t.Errorf("Predecessor(42) error = %v on a new tree, want %v", err, bstrees.ErrPredecessorDoesNotExist)
+	}
+	if _, err := tree.Successor(42); !errors.Is(err, bstrees.ErrSuccessorDoesNotExist) {
+		t.Errorf("Successor(42) error = %v on a new tree, want %v", err, bstrees.ErrSuccessorDoesNotExist)
+	}
+
+	// Deleting from an empty tree is a no-op
+	tree.Delete(42)
+	if !tree.Empty() || tree.Size() != 0 {
+		t.Error("Delete(42) changed an empty tree")
+	}
+}
+
+func testSequential(t *testing.T, tree bstrees.Tree[int]) {
+	m := &model{}
+	for i := 1; i <= 100; i++ {
+		tree.Insert(i * 2)
+		m.insert(i * 2)
+		check(t, tree, m, 0, 202)
+	}
+	for i := 100; i >= 1; i -= 3 {
+		tree.Delete(i * 2)
+		m.delete(i * 2)
+		tree.Delete(i*2 + 1) // Not in the tree
+		check(t, tree, m, 0, 202)
+	}
+}
+
+func testDuplicates(t *testing.T, tree bstrees.Tree[int]) {
+	m := &model{}
+	for _, value := range []int{5, 3, 5, 5, 8, 3, 5, 1, 8, 5} {
+		tree.Insert(value)
+		m.insert(value)
+		check(t, tree, m, 0, 10)
+	}
+	for _, value := range []int{5, 5, 3, 8, 5, 1, 5, 5, 3, 8} {
+		tree.Delete(value)
+		m.delete(value)
+		check(t, tree, m, 0, 10)
+	}
+	if !tree.Empty() {
+		t.Errorf("Empty() = false after deleting every value")
+	}
+}
+
+func testClear(t *testing.T, tree bstrees.Tree[int]) {
+	for i := 0; i < 10; i++ {
+		tree.Insert(i)
+	}
+	tree.Clear()
+	check(t, tree, &model{}, -1, 11)
+	tree.Insert(3)
+	check(t, tree, &model{values: []int{3}}, -1, 11)
+}
+
+func testRandom(t *testing.T, tree bstrees.Tree[int]) {
+	r := rand.New(rand.NewSource(1))
+	m := &model{}
+	for i := 0; i < 2000; i++ {
+		value := r.Intn(200)
+		if r.Intn(3) == 0 {
+			tree.Delete(value)
+			m.delete(value)
+		} else {
+			if !tree.Insert(value) {
+				t.Fatalf("Insert(%d) = false on a multiset", value)
+			}
+			m.insert(value)
+		}
+		if i%100 == 0 {
+			check(t, tree, m, -1, 201)
+		}
+	}
+	check(t, tree, m, -1, 201)
+	for len(m.values) > 0 {
+		value := m.values[r.Intn(len(m.values))]
+		tree.Delete(value)
+		m.delete(value)
+	}
+	check(t, tree, m, -1, 201)
+}
+
+// validator is implemented by the trees that can check their own invariants.
+type validator interface {
+	Validate() error
+}
+
+// check compares every query of tree against m, for all values in [lo, hi].
+func check(t *testing.T, tree bstrees.Tree[int], m *model, lo, hi int) {
+	t.Helper()
+	if v, ok := tree.(validator); ok {
+		if err := v.Validate(); err != nil {
+			t.Fatalf("Validate() = %v", err)
+		}
+	}
+	if size := tree.Size(); size != uint(len(m.values)) {
+		t.Fatalf("Size() = %d, want %d", size, len(m.values))
+	}
+	if empty := tree.Empty(); empty != (len(m.values) == 0) {
+		t.Fatalf("Empty() = %v, want %v", empty, len(m.values) == 0)
+	}
+	for i, want := range m.values {
+		got, err := tree.At(uint(i + 1))
+		if err != nil || got != want {
+			t.Fatalf("At(%d) = %d, %v, want %d, nil", i+1, got, err, want)
+		}
+	}
+	if _, err := tree.At(uint(len(m.values) + 1)); !errors.Is(err, bstrees.ErrIndexIsOutOfRange) {
+		t.Fatalf("At(%d) error = %v, want %v", len(m.values)+1, err, bstrees.ErrIndexIsOutOfRange)
+	}
+	for value := lo; value <= hi; value++ {
+		if got, want := tree.Contains(value), m.contains(value); got != want {
+			t.Fatalf("Contains(%d) = %v, want %v", value, got, want)
+		}
+		if got, want := tree.Index(value), m.index(value); got != want {
+			t.Fatalf("Index(%d) = %d, want %d", value, got, want)
+		}
+		got, err := tree.Predecessor(value)
+		if want, ok := m.predecessor(value); !ok {
+			if !errors.Is(err, bstrees.ErrPredecessorDoesNotExist) {
+				t.Fatalf("Predecessor(%d) = %d, %v, want %v", value, got, err, bstrees.ErrPredecessorDoesNotExist)
+			}
+		} else if err != nil || got != want {
+			t.Fatalf("Predecessor(%d) = %d, %v, want %d, nil", value, got, err, want)
+		}
+		got, err = tree.Successor(value)
+		if want, ok := m.successor(value); !ok {
+			if !errors.Is(err, bstrees.ErrSuccessorDoesNotExist) {
+				t.Fatalf("Successor(%d) = %d, %v, want %v", value, got, err, bstrees.ErrSuccessorDoesNotExist)
+			}
+		} else if err != nil || got != want {
+			t.Fatalf("Successor(%d) = %d, %v, want %d, nil", value, got, err, want)
+		}
+	}
+}
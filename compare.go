@@ -0,0 +1,25 @@
+package bstrees
+
+import "golang.org/x/exp/constraints"
+
+// Compare is the comparator used by the New constructors. It returns -1, 0 or
+// +1 when a is less than, equal to or greater than b. A NaN is considered less
+// than any other float and equal to another NaN.
+func Compare[T constraints.Ordered](a, b T) int {
+	aNaN := a != a
+	bNaN := b != b
+	if aNaN || bNaN {
+		if aNaN && bNaN {
+			return 0
+		} else if aNaN {
+			return -1
+		}
+		return +1
+	}
+	if a < b {
+		return -1
+	} else if b < a {
+		return +1
+	}
+	return 0
+}
@@ -0,0 +1,32 @@
+package bstrees_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+func TestCompare(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		a, b float64
+		want int
+	}{
+		{1, 2, -1},
+		{2, 1, +1},
+		{1, 1, 0},
+		{math.Inf(-1), 0, -1},
+		{nan, math.Inf(-1), -1},
+		{0, nan, +1},
+		{nan, nan, 0},
+	}
+	for _, test := range tests {
+		if got := bstrees.Compare(test.a, test.b); got != test.want {
+			t.Errorf("Compare(%v, %v) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+	if got := bstrees.Compare("apple", "banana"); got != -1 {
+		t.Errorf(`Compare("apple", "banana") = %d, want -1`, got)
+	}
+}
@@ -2,10 +2,24 @@ package bstrees
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
 	ErrIndexIsOutOfRange       = errors.New("index is out of range")
 	ErrPredecessorDoesNotExist = errors.New("predecessor does not exist")
 	ErrSuccessorDoesNotExist   = errors.New("successor does not exist")
+	ErrTreeIsNotInitialized    = errors.New("tree is not initialized")
+	ErrDataIsCorrupted         = errors.New("data is corrupted")
 )
+
+// InvariantError is returned by Validate for the first node found to break a
+// rule of its tree.
+type InvariantError struct {
+	Value any    // Value of the offending node
+	Rule  string // Rule it breaks
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("node %v: %s", e.Value, e.Rule)
+}
@@ -1,10 +1,9 @@
 package fhq
 
-import (
-	"golang.org/x/exp/constraints"
-)
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
 
-func merge[T constraints.Integer | constraints.Float](left *fhqTreapNode[T], right *fhqTreapNode[T]) *fhqTreapNode[T] {
+func merge[T any](left *fhqTreapNode[T], right *fhqTreapNode[T], counter *stats.Counter) *fhqTreapNode[T] {
+	counter.Merge()
 	if left == nil {
 		return right
 	}
@@ -12,29 +11,100 @@ func merge[T constraints.Integer | constraints.Float](left *fhqTreapNode[T], rig
 		return left
 	}
 	if left.weight < right.weight {
-		left.right = merge(left.right, right)
+		left.right = merge(left.right, right, counter)
 		left.Update()
 		return left
 	} else {
-		right.left = merge(left, right.left)
+		right.left = merge(left, right.left, counter)
 		right.Update()
 		return right
 	}
 }
 
-func split[T constraints.Integer | constraints.Float](root *fhqTreapNode[T], key T) (*fhqTreapNode[T], *fhqTreapNode[T]) {
+// Split root into values <= key and values > key
+func split[T any](root *fhqTreapNode[T], key T, cmp func(a, b T) int, counter *stats.Counter) (*fhqTreapNode[T], *fhqTreapNode[T]) {
+	counter.Split()
 	if root == nil {
 		return nil, nil
 	}
-	if root.value <= key {
-		left, right := split(root.right, key)
+	if cmp(root.value, key) <= 0 {
+		left, right := split(root.right, key, cmp, counter)
 		root.right = left
 		root.Update()
 		return root, right
 	} else {
-		left, right := split(root.left, key)
+		left, right := split(root.left, key, cmp, counter)
 		root.left = right
 		root.Update()
 		return left, root
 	}
 }
+
+// Split root into values < key and values >= key
+func splitLess[T any](root *fhqTreapNode[T], key T, cmp func(a, b T) int, counter *stats.Counter) (*fhqTreapNode[T], *fhqTreapNode[T]) {
+	counter.Split()
+	if root == nil {
+		return nil, nil
+	}
+	if cmp(root.value, key) < 0 {
+		left, right := splitLess(root.right, key, cmp, counter)
+		root.right = left
+		root.Update()
+		return root, right
+	} else {
+		left, right := splitLess(root.left, key, cmp, counter)
+		root.left = right
+		root.Update()
+		return left, root
+	}
+}
+
+// Split root into the k smallest values and the others
+func splitSize[T any](root *fhqTreapNode[T], k uint, counter *stats.Counter) (*fhqTreapNode[T], *fhqTreapNode[T]) {
+	counter.Split()
+	if root == nil {
+		return nil, nil
+	}
+	leftSize := uint(0)
+	if root.left != nil {
+		leftSize = root.left.size
+	}
+	if leftSize < k {
+		left, right := splitSize(root.right, k-leftSize-1, counter)
+		root.right = left
+		root.Update()
+		return root, right
+	} else {
+		left, right := splitSize(root.left, k, counter)
+		root.left = right
+		root.Update()
+		return left, root
+	}
+}
+
+// Build a treap from sorted values as a Cartesian tree on the random weights,
+// keeping the right spine in a stack.
+func fromSorted[T any](values []T) *fhqTreapNode[T] {
+	var stack []*fhqTreapNode[T]
+	for _, value := range values {
+		node := newFHQTreapNode(value)
+		var last *fhqTreapNode[T] = nil
+		for len(stack) > 0 && stack[len(stack)-1].weight > node.weight {
+			last = stack[len(stack)-1]
+			last.Update()
+			stack = stack[:len(stack)-1]
+		}
+		node.left = last
+		if len(stack) > 0 {
+			stack[len(stack)-1].right = node
+		}
+		stack = append(stack, node)
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].Update()
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+	return stack[0]
+}
@@ -0,0 +1,162 @@
+package fhq
+
+import (
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/invariant"
+)
+
+// Append the nodes of root in pre-order
+func encodeNodes[T any](root *fhqTreapNode[T], nodes []codec.Node[T]) []codec.Node[T] {
+	if root == nil {
+		return nodes
+	}
+	nodes = append(nodes, codec.Node[T]{Value: root.value, Left: root.left != nil, Right: root.right != nil, Weight: root.weight})
+	nodes = encodeNodes(root.left, nodes)
+	return encodeNodes(root.right, nodes)
+}
+
+// Rebuild a node from its serialized form
+func decodeNode[T any](n *codec.Node[T], left, right *fhqTreapNode[T]) *fhqTreapNode[T] {
+	node := &fhqTreapNode[T]{value: n.Value, left: left, right: right, weight: n.Weight}
+	node.Update()
+	return node
+}
+
+func encode[T any](root *fhqTreapNode[T], o options) *codec.Tree[T] {
+	tree := new(codec.Tree[T])
+	if o.shape {
+		tree.Nodes = encodeNodes(root, make([]codec.Node[T], 0, size(root)))
+	} else {
+		tree.Values = make([]T, 0, size(root))
+		ascend(root, func(value T) bool {
+			tree.Values = append(tree.Values, value)
+			return true
+		})
+	}
+	return tree
+}
+
+func decode[T any](tree *codec.Tree[T], cmp func(a, b T) int, o options) (*fhqTreapNode[T], error) {
+	if cmp == nil {
+		return nil, bstrees.ErrTreeIsNotInitialized
+	}
+	if len(tree.Nodes) != 0 {
+		root, err := codec.Build(tree.Nodes, decodeNode[T])
+		if err != nil {
+			return nil, err
+		}
+		if err := validate(root, invariant.NewOrder(cmp, o.unique)); err != nil {
+			return nil, codec.Corrupted(err)
+		}
+		return root, nil
+	}
+	if !codec.Sorted(tree.Values, cmp, false) {
+		return nil, bstrees.ErrDataIsCorrupted
+	}
+	values := tree.Values
+	if o.unique {
+		values = codec.Unique(values, cmp)
+	}
+	return fromSorted(values), nil
+}
+
+func (t *FHQTreap[T]) encode() *codec.Tree[T] {
+	return encode(t.root, t.options)
+}
+
+func (t *FHQTreap[T]) decode(tree *codec.Tree[T]) error {
+	root, err := decode(tree, t.cmp, t.options)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+// MarshalBinary encodes the values of t in ascending order, or its nodes with
+// their weights if t has been created with PreserveShape.
+func (t *FHQTreap[T]) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(t.encode())
+}
+
+// UnmarshalBinary replaces the values of t with the ones encoded by
+// MarshalBinary, in O(n) whichever the encoding. t must have been created by a
+// constructor, and keeps its own options.
+func (t *FHQTreap[T]) UnmarshalBinary(data []byte) error {
+	tree, err := codec.UnmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+// MarshalJSON encodes t like MarshalBinary, as a JSON object.
+func (t *FHQTreap[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(t.encode())
+}
+
+// UnmarshalJSON decodes t like UnmarshalBinary.
+func (t *FHQTreap[T]) UnmarshalJSON(data []byte) error {
+	tree, err := codec.UnmarshalJSON[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+func (t *FHQTreap[T]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+func (t *FHQTreap[T]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
+
+// MarshalBinary encodes the version p like FHQTreap.MarshalBinary.
+func (p *Persistent[T]) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(encode(p.root, p.options))
+}
+
+// UnmarshalBinary makes p hold the version encoded by MarshalBinary, the
+// versions p has been derived from are left as they are. p must have been
+// created by a constructor, and keeps its own options.
+func (p *Persistent[T]) UnmarshalBinary(data []byte) error {
+	tree, err := codec.UnmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	root, err := decode(tree, p.cmp, p.options)
+	if err != nil {
+		return err
+	}
+	p.root = root
+	return nil
+}
+
+// MarshalJSON encodes p like MarshalBinary, as a JSON object.
+func (p *Persistent[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(encode(p.root, p.options))
+}
+
+// UnmarshalJSON decodes p like UnmarshalBinary.
+func (p *Persistent[T]) UnmarshalJSON(data []byte) error {
+	tree, err := codec.UnmarshalJSON[T](data)
+	if err != nil {
+		return err
+	}
+	root, err := decode(tree, p.cmp, p.options)
+	if err != nil {
+		return err
+	}
+	p.root = root
+	return nil
+}
+
+func (p *Persistent[T]) GobEncode() ([]byte, error) {
+	return p.MarshalBinary()
+}
+
+func (p *Persistent[T]) GobDecode(data []byte) error {
+	return p.UnmarshalBinary(data)
+}
@@ -2,22 +2,54 @@ package fhq
 
 import (
 	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
 	"golang.org/x/exp/constraints"
 )
 
-type FHQTreap[T constraints.Integer | constraints.Float] struct {
+var _ bstrees.Tree[int] = (*FHQTreap[int])(nil)
+
+// FHQTreap must be created by a constructor such as New or NewFunc.
+type FHQTreap[T any] struct {
 	root *fhqTreapNode[T]
+	cmp  func(a, b T) int
+	options
+	counter *stats.Counter // Shared with the trees made from this one
+}
+
+func New[T constraints.Ordered](opts ...Option) *FHQTreap[T] {
+	return NewFunc(bstrees.Compare[T], opts...)
+}
+
+// NewFunc creates a tree ordered by cmp, which returns a negative number when
+// a < b, a positive number when a > b and zero when a == b.
+func NewFunc[T any](cmp func(a, b T) int, opts ...Option) *FHQTreap[T] {
+	counter := new(stats.Counter)
+	return &FHQTreap[T]{root: nil, cmp: stats.Compare(counter, cmp), options: newOptions(opts), counter: counter}
+}
+
+// FromSorted builds a tree from values sorted in ascending order in O(n).
+// The order of values is not checked.
+func FromSorted[T constraints.Ordered](values []T, opts ...Option) *FHQTreap[T] {
+	return FromSortedFunc(bstrees.Compare[T], values, opts...)
 }
 
-func New[T constraints.Integer | constraints.Float]() *FHQTreap[T] {
-	return &FHQTreap[T]{root: nil}
+// FromSortedFunc builds a tree ordered by cmp from values sorted by cmp in
+// O(n), see NewFunc and FromSorted.
+func FromSortedFunc[T any](cmp func(a, b T) int, values []T, opts ...Option) *FHQTreap[T] {
+	t := NewFunc(cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
+	}
+	t.root = fromSorted(values)
+	return t
 }
 
-func search[T constraints.Integer | constraints.Float](root *fhqTreapNode[T], value T) *fhqTreapNode[T] {
+func search[T any](root *fhqTreapNode[T], value T, cmp func(a, b T) int) *fhqTreapNode[T] {
 	for root != nil {
-		if value < root.value {
+		if c := cmp(value, root.value); c < 0 {
 			root = root.left
-		} else if root.value < value {
+		} else if c > 0 {
 			root = root.right
 		} else {
 			return root
@@ -26,7 +58,7 @@ func search[T constraints.Integer | constraints.Float](root *fhqTreapNode[T], va
 	return nil
 }
 
-func At[T constraints.Integer | constraints.Float](root *fhqTreapNode[T], k uint) *fhqTreapNode[T] {
+func At[T any](root *fhqTreapNode[T], k uint) *fhqTreapNode[T] {
 	for root != nil {
 		leftSize := uint(0)
 		if root.left != nil {
@@ -44,28 +76,57 @@ func At[T constraints.Integer | constraints.Float](root *fhqTreapNode[T], k uint
 	return nil
 }
 
-func (t *FHQTreap[T]) Insert(value T) {
-	left, right := split(t.root, value)
-	t.root = merge(merge(left, newFHQTreapNode(value)), right)
+func (t *FHQTreap[T]) Insert(value T) bool {
+	_, inserted := t.insert(value)
+	return inserted
+}
+
+// Same as Insert, but also returns the node holding value
+func (t *FHQTreap[T]) insert(value T) (*fhqTreapNode[T], bool) {
+	left, right := split(t.root, value, t.cmp, t.counter)
+	if t.unique {
+		var mid *fhqTreapNode[T]
+		left, mid = splitLess(left, value, t.cmp, t.counter)
+		if mid != nil {
+			t.root = merge(merge(left, mid, t.counter), right, t.counter)
+			return mid, false
+		}
+	}
+	node := newFHQTreapNode(value)
+	t.root = merge(merge(left, node, t.counter), right, t.counter)
+	return node, true
 }
 
 func (t *FHQTreap[T]) Delete(value T) {
-	left, right := split(t.root, value)
-	left, mid := split(left, value-1)
-	if mid != nil {
-		mid = merge(mid.left, mid.right)
+	t.delete(value)
+}
+
+// Same as Delete, but also returns whether value has been deleted
+func (t *FHQTreap[T]) delete(value T) bool {
+	left, right := split(t.root, value, t.cmp, t.counter)
+	left, mid := splitLess(left, value, t.cmp, t.counter)
+	deleted := mid != nil
+	if deleted {
+		mid = merge(mid.left, mid.right, t.counter)
 	}
-	t.root = merge(merge(left, mid), right)
+	t.root = merge(merge(left, mid, t.counter), right, t.counter)
+	return deleted
 }
 
 func (t *FHQTreap[T]) Contains(value T) bool {
-	return search(t.root, value) != nil
+	return search(t.root, value, t.cmp) != nil
 }
 
 func (t *FHQTreap[T]) Index(value T) uint {
-	left, right := split(t.root, value-1)
+	return index(t.root, value, t.cmp)
+}
+
+// IndexSplit is Index computed by splitting the tree and merging it back, so
+// it modifies the tree.
+func (t *FHQTreap[T]) IndexSplit(value T) uint {
+	left, right := splitLess(t.root, value, t.cmp, t.counter)
 	defer func() {
-		t.root = merge(left, right)
+		t.root = merge(left, right, t.counter)
 	}()
 	if left == nil {
 		return 1
@@ -76,7 +137,8 @@ func (t *FHQTreap[T]) Index(value T) uint {
 func (t *FHQTreap[T]) At(k uint) (T, error) {
 	result := At(t.root, k)
 	if result == nil {
-		return T(0), bstrees.ErrIndexIsOutOfRange
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
 	}
 	return result.value, nil
 }
@@ -96,26 +158,244 @@ func (t *FHQTreap[T]) Clear() {
 	t.root = nil
 }
 
+func predecessor[T any](root *fhqTreapNode[T], value T, cmp func(a, b T) int) *fhqTreapNode[T] {
+	var result *fhqTreapNode[T] = nil
+	for root != nil {
+		if cmp(root.value, value) < 0 {
+			result = root
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return result
+}
+
 func (t *FHQTreap[T]) Predecessor(value T) (T, error) {
-	left, right := split(t.root, value-1)
+	result := predecessor(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
+	}
+	return result.value, nil
+}
+
+// PredecessorSplit is Predecessor computed by splitting the tree and merging
+// it back, so it modifies the tree.
+func (t *FHQTreap[T]) PredecessorSplit(value T) (T, error) {
+	left, right := splitLess(t.root, value, t.cmp, t.counter)
 	defer func() {
-		t.root = merge(left, right)
+		t.root = merge(left, right, t.counter)
 	}()
-	result := At(left, left.size)
+	if left == nil {
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
+	}
+	return At(left, left.size).value, nil
+}
+
+func successor[T any](root *fhqTreapNode[T], value T, cmp func(a, b T) int) *fhqTreapNode[T] {
+	var result *fhqTreapNode[T] = nil
+	for root != nil {
+		if cmp(root.value, value) > 0 {
+			result = root
+			root = root.left
+		} else {
+			root = root.right
+		}
+	}
+	return result
+}
+
+func (t *FHQTreap[T]) Successor(value T) (T, error) {
+	result := successor(t.root, value, t.cmp)
 	if result == nil {
-		return T(0), bstrees.ErrPredecessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
 	return result.value, nil
 }
 
-func (t *FHQTreap[T]) Successor(value T) (T, error) {
-	left, right := split(t.root, value)
+// SuccessorSplit is Successor computed by splitting the tree and merging it
+// back, so it modifies the tree.
+func (t *FHQTreap[T]) SuccessorSplit(value T) (T, error) {
+	left, right := split(t.root, value, t.cmp, t.counter)
 	defer func() {
-		t.root = merge(left, right)
+		t.root = merge(left, right, t.counter)
 	}()
 	result := At(right, 1)
 	if result == nil {
-		return T(0), bstrees.ErrSuccessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
 	return result.value, nil
 }
+
+func floor[T any](root *fhqTreapNode[T], value T, cmp func(a, b T) int) *fhqTreapNode[T] {
+	var result *fhqTreapNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c < 0 {
+			result = root
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return result
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *FHQTreap[T]) Floor(value T) (T, bool) {
+	result := floor(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func ceiling[T any](root *fhqTreapNode[T], value T, cmp func(a, b T) int) *fhqTreapNode[T] {
+	var result *fhqTreapNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c > 0 {
+			result = root
+			root = root.left
+		} else {
+			root = root.right
+		}
+	}
+	return result
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *FHQTreap[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func minimum[T any](root *fhqTreapNode[T]) *fhqTreapNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.left != nil {
+		root = root.left
+	}
+	return root
+}
+
+func maximum[T any](root *fhqTreapNode[T]) *fhqTreapNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.right != nil {
+		root = root.right
+	}
+	return root
+}
+
+// Min returns the smallest value.
+func (t *FHQTreap[T]) Min() (T, bool) {
+	result := minimum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *FHQTreap[T]) Max() (T, bool) {
+	result := maximum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *FHQTreap[T]) PopMin() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	left, right := splitSize(t.root, 1, t.counter)
+	t.root = right
+	return left.value, true
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *FHQTreap[T]) PopMax() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	left, right := splitSize(t.root, t.root.size-1, t.counter)
+	t.root = left
+	return right.value, true
+}
+
+// Rank of the least value greater than or equal to value
+func index[T any](root *fhqTreapNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) < 0 {
+			rank += 1
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Rank of the least value greater than value
+func upperIndex[T any](root *fhqTreapNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) <= 0 {
+			rank += 1
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Count returns the number of copies of value.
+func (t *FHQTreap[T]) Count(value T) uint {
+	first, last := t.EqualRange(value)
+	return last - first
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *FHQTreap[T]) EqualRange(value T) (first, last uint) {
+	return index(t.root, value, t.cmp), upperIndex(t.root, value, t.cmp)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *FHQTreap[T]) DeleteAll(value T) uint {
+	left, right := split(t.root, value, t.cmp, t.counter)
+	left, mid := splitLess(left, value, t.cmp, t.counter)
+	t.root = merge(left, right, t.counter)
+	if mid == nil {
+		return 0
+	}
+	return mid.size
+}
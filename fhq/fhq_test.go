@@ -0,0 +1,222 @@
+package fhq_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+	"github.com/yanglinshu/bstrees/v2/fhq"
+)
+
+func TestTree(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return fhq.New[int]() })
+}
+
+func TestTreeFunc(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return fhq.NewFunc(func(a, b int) int { return a - b })
+	})
+}
+
+// splitTree answers the queries of the conformance suite by splitting
+type splitTree struct {
+	*fhq.FHQTreap[int]
+}
+
+func (t splitTree) Index(value int) uint {
+	return t.IndexSplit(value)
+}
+
+func (t splitTree) Predecessor(value int) (int, error) {
+	return t.PredecessorSplit(value)
+}
+
+func (t splitTree) Successor(value int) (int, error) {
+	return t.SuccessorSplit(value)
+}
+
+func TestTreeSplit(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return splitTree{fhq.New[int]()} })
+}
+
+func TestMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return fhq.NewMap[int, int]() })
+}
+
+func TestFloat(t *testing.T) {
+	tree := fhq.New[float64]()
+	for _, value := range []float64{1.2, 1.5, 1.7, 2.1} {
+		tree.Insert(value)
+	}
+	if got, err := tree.Predecessor(1.5); err != nil || got != 1.2 {
+		t.Errorf("Predecessor(1.5) = %v, %v, want 1.2, nil", got, err)
+	}
+	if got, err := tree.Successor(1.5); err != nil || got != 1.7 {
+		t.Errorf("Successor(1.5) = %v, %v, want 1.7, nil", got, err)
+	}
+	if got, ok := tree.Floor(1.6); !ok || got != 1.5 {
+		t.Errorf("Floor(1.6) = %v, %v, want 1.5, true", got, ok)
+	}
+	if got, ok := tree.Ceiling(1.6); !ok || got != 1.7 {
+		t.Errorf("Ceiling(1.6) = %v, %v, want 1.7, true", got, ok)
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	bstreestest.RunOrderedSuite(t, func() bstrees.Tree[string] { return fhq.New[string]() },
+		func(values []uint) bstrees.Tree[uint] { return fhq.FromSorted(values) })
+}
+
+func TestSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return fhq.New[int](fhq.Unique()) })
+}
+
+func TestFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return fhq.FromSorted(values) })
+}
+
+func TestFromSortedUnique(t *testing.T) {
+	tree := fhq.FromSorted([]int{1, 1, 2, 3, 3, 3}, fhq.Unique())
+	if size := tree.Size(); size != 3 {
+		t.Errorf("Size() = %d, want 3", size)
+	}
+	if tree.Insert(2) {
+		t.Error("Insert(2) = true, want false")
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *fhq.FHQTreap[int] { return fhq.New[int]() },
+		(*fhq.FHQTreap[int]).SplitAt, (*fhq.FHQTreap[int]).SplitRank, fhq.Join[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *fhq.FHQTreap[int] { return fhq.New[int]() },
+		fhq.Union[int], fhq.Intersection[int], fhq.Difference[int])
+}
+
+func TestSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *fhq.FHQTreap[int] { return fhq.New[int](fhq.Unique()) },
+		fhq.Union[int], fhq.Intersection[int], fhq.Difference[int])
+}
+
+// persistentTree runs Persistent through the conformance suite by always
+// holding the latest version
+type persistentTree struct {
+	*fhq.Persistent[int]
+}
+
+func (t *persistentTree) Insert(value int) bool {
+	next := t.Persistent.Insert(value)
+	inserted := next != t.Persistent
+	t.Persistent = next
+	return inserted
+}
+
+func (t *persistentTree) Delete(value int) {
+	t.Persistent = t.Persistent.Delete(value)
+}
+
+func (t *persistentTree) Clear() {
+	t.Persistent = fhq.NewPersistent[int]()
+}
+
+func TestPersistent(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return &persistentTree{fhq.NewPersistent[int]()}
+	})
+}
+
+func TestPersistentSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] {
+		return &persistentTree{fhq.NewPersistent[int](fhq.Unique())}
+	})
+}
+
+func TestPersistentEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *persistentTree { return &persistentTree{fhq.NewPersistent[int]()} },
+		func() *persistentTree { return &persistentTree{fhq.NewPersistent[int](fhq.PreserveShape())} })
+}
+
+func TestPersistentVersions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	versions := []*fhq.Persistent[int]{fhq.NewPersistent[int]()}
+	models := [][]int{nil}
+	for i := 0; i < 300; i++ {
+		value := r.Intn(50)
+		latest, model := versions[len(versions)-1], models[len(models)-1]
+		k := sort.SearchInts(model, value)
+		next := append([]int{}, model...)
+		if r.Intn(3) == 0 {
+			latest = latest.Delete(value)
+			if k < len(model) && model[k] == value {
+				next = append(next[:k], next[k+1:]...)
+			}
+		} else {
+			latest = latest.Insert(value)
+			next = append(next[:k], append([]int{value}, next[k:]...)...)
+		}
+		versions, models = append(versions, latest), append(models, next)
+	}
+	for i, version := range versions {
+		model := models[i]
+		if size := version.Size(); size != uint(len(model)) {
+			t.Fatalf("version %d: Size() = %d, want %d", i, size, len(model))
+		}
+		for k, want := range model {
+			if got, err := version.At(uint(k + 1)); err != nil || got != want {
+				t.Fatalf("version %d: At(%d) = %d, %v, want %d, nil", i, k+1, got, err, want)
+			}
+		}
+		for value := -1; value <= 50; value++ {
+			k := sort.SearchInts(model, value)
+			if got := version.Index(value); got != uint(k+1) {
+				t.Fatalf("version %d: Index(%d) = %d, want %d", i, value, got, k+1)
+			}
+			if got, err := version.Predecessor(value); k > 0 && (err != nil || got != model[k-1]) {
+				t.Fatalf("version %d: Predecessor(%d) = %d, %v, want %d, nil", i, value, got, err, model[k-1])
+			} else if k == 0 && err == nil {
+				t.Fatalf("version %d: Predecessor(%d) = %d, want an error", i, value, got)
+			}
+			upper := sort.SearchInts(model, value+1)
+			if got, err := version.Successor(value); upper < len(model) && (err != nil || got != model[upper]) {
+				t.Fatalf("version %d: Successor(%d) = %d, %v, want %d, nil", i, value, got, err, model[upper])
+			} else if upper == len(model) && err == nil {
+				t.Fatalf("version %d: Successor(%d) = %d, want an error", i, value, got)
+			}
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *fhq.FHQTreap[int] { return fhq.New[int]() },
+		func() *fhq.FHQTreap[int] { return fhq.New[int](fhq.PreserveShape()) })
+}
+
+func TestString(t *testing.T) {
+	tree := fhq.FromSorted([]int{1, 2, 3})
+	if got := tree.String(); strings.Count(got, "weight=") != 3 {
+		t.Errorf("String() = \n%s\nwant 3 weighted nodes", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := fhq.New[int]()
+	data := `{"nodes":[{"value":1,"right":true,"weight":5},{"value":2,"weight":3}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 1: child 2 has weight 3, less than 5") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 1: child 2 has weight 3, less than 5")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func BenchmarkRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return fhq.New[int]() })
+}
@@ -0,0 +1,31 @@
+//go:build go1.23
+
+package fhq
+
+import "iter"
+
+// All returns an iterator over the values in ascending order.
+func (t *FHQTreap[T]) All() iter.Seq[T] {
+	return t.Ascend
+}
+
+// Backward returns an iterator over the values in descending order.
+func (t *FHQTreap[T]) Backward() iter.Seq[T] {
+	return t.Descend
+}
+
+// Range returns an iterator over the values in [greaterOrEqual, lessThan) in
+// ascending order.
+func (t *FHQTreap[T]) Range(greaterOrEqual, lessThan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.AscendRange(greaterOrEqual, lessThan, yield)
+	}
+}
+
+// RangeBackward returns an iterator over the values in
+// (greaterThan, lessOrEqual] in descending order.
+func (t *FHQTreap[T]) RangeBackward(lessOrEqual, greaterThan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.DescendRange(lessOrEqual, greaterThan, yield)
+	}
+}
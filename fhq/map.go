@@ -0,0 +1,47 @@
+package fhq
+
+import (
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/ordmap"
+	"golang.org/x/exp/constraints"
+)
+
+var _ bstrees.Map[int, int] = (*Map[int, int])(nil)
+
+// Map is an ordered map stored in a FHQTreap, with at most one value per key.
+// Map must be created by NewMap or NewMapFunc.
+type Map[K, V any] struct {
+	ordmap.Map[K, V]
+}
+
+func NewMap[K constraints.Ordered, V any]() *Map[K, V] {
+	return NewMapFunc[K, V](bstrees.Compare[K])
+}
+
+// NewMapFunc creates a map ordered by cmp, see NewFunc.
+func NewMapFunc[K, V any](cmp func(a, b K) int) *Map[K, V] {
+	tree := NewFunc(func(a, b ordmap.Entry[K, V]) int {
+		return cmp(a.Key, b.Key)
+	}, Unique())
+	return &Map[K, V]{ordmap.New[K, V](mapTree[K, V]{tree})}
+}
+
+type mapTree[K, V any] struct {
+	*FHQTreap[ordmap.Entry[K, V]]
+}
+
+func (t mapTree[K, V]) Search(key K) *ordmap.Entry[K, V] {
+	if node := search(t.root, ordmap.Entry[K, V]{Key: key}, t.cmp); node != nil {
+		return &node.value
+	}
+	return nil
+}
+
+func (t mapTree[K, V]) Upsert(key K) (*ordmap.Entry[K, V], bool) {
+	node, inserted := t.insert(ordmap.Entry[K, V]{Key: key})
+	return &node.value, !inserted
+}
+
+func (t mapTree[K, V]) Remove(key K) bool {
+	return t.delete(ordmap.Entry[K, V]{Key: key})
+}
@@ -1,12 +1,8 @@
 package fhq
 
-import (
-	"math/rand"
+import "math/rand"
 
-	"golang.org/x/exp/constraints"
-)
-
-type fhqTreapNode[T constraints.Ordered] struct {
+type fhqTreapNode[T any] struct {
 	value  T
 	left   *fhqTreapNode[T]
 	right  *fhqTreapNode[T]
@@ -14,7 +10,7 @@ type fhqTreapNode[T constraints.Ordered] struct {
 	size   uint // Size of subtree, unnecessary if you don't need kth element
 }
 
-func newFHQTreapNode[T constraints.Ordered](value T) *fhqTreapNode[T] {
+func newFHQTreapNode[T any](value T) *fhqTreapNode[T] {
 	return &fhqTreapNode[T]{value: value, left: nil, right: nil, weight: uint(rand.Uint32()), size: 1}
 }
 
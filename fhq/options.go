@@ -0,0 +1,39 @@
+package fhq
+
+// Option configures a tree created by New or NewFunc.
+type Option func(*options)
+
+type options struct {
+	unique bool
+	shape  bool
+}
+
+// Unique makes the tree a set: Insert leaves the tree unchanged and returns
+// false when an equal value is already present.
+func Unique() Option {
+	return func(o *options) {
+		o.unique = true
+	}
+}
+
+// PreserveShape makes the tree marshal its nodes with their balancing data
+// instead of its sorted values only, so that it is unmarshaled into the very
+// same shape.
+func PreserveShape() Option {
+	return func(o *options) {
+		o.shape = true
+	}
+}
+
+// IsSet reports whether the tree has been created with Unique.
+func (o options) IsSet() bool {
+	return o.unique
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
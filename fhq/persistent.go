@@ -0,0 +1,215 @@
+package fhq
+
+import (
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
+	"golang.org/x/exp/constraints"
+)
+
+// Persistent is an immutable FHQ treap. Insert and Delete leave it unchanged
+// and return a new version sharing all but O(log n) nodes with it, so every
+// version can still be queried. Persistent must be created by a constructor
+// such as NewPersistent or NewPersistentFunc.
+type Persistent[T any] struct {
+	root *fhqTreapNode[T]
+	cmp  func(a, b T) int
+	options
+	counter *stats.Counter // Shared with the versions made from this one
+}
+
+// NewPersistent creates an empty version.
+func NewPersistent[T constraints.Ordered](opts ...Option) *Persistent[T] {
+	return NewPersistentFunc(bstrees.Compare[T], opts...)
+}
+
+// NewPersistentFunc creates an empty version ordered by cmp, see NewFunc.
+func NewPersistentFunc[T any](cmp func(a, b T) int, opts ...Option) *Persistent[T] {
+	counter := new(stats.Counter)
+	return &Persistent[T]{root: nil, cmp: stats.Compare(counter, cmp), options: newOptions(opts), counter: counter}
+}
+
+func (n *fhqTreapNode[T]) clone() *fhqTreapNode[T] {
+	clone := *n
+	return &clone
+}
+
+// Same as merge, but copies the nodes it changes instead of modifying them
+func mergeCopy[T any](left *fhqTreapNode[T], right *fhqTreapNode[T], counter *stats.Counter) *fhqTreapNode[T] {
+	counter.Merge()
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.weight < right.weight {
+		left = left.clone()
+		left.right = mergeCopy(left.right, right, counter)
+		left.Update()
+		return left
+	} else {
+		right = right.clone()
+		right.left = mergeCopy(left, right.left, counter)
+		right.Update()
+		return right
+	}
+}
+
+// Same as split, but copies the nodes it changes instead of modifying them
+func splitCopy[T any](root *fhqTreapNode[T], key T, cmp func(a, b T) int, counter *stats.Counter) (*fhqTreapNode[T], *fhqTreapNode[T]) {
+	counter.Split()
+	if root == nil {
+		return nil, nil
+	}
+	root = root.clone()
+	if cmp(root.value, key) <= 0 {
+		left, right := splitCopy(root.right, key, cmp, counter)
+		root.right = left
+		root.Update()
+		return root, right
+	} else {
+		left, right := splitCopy(root.left, key, cmp, counter)
+		root.left = right
+		root.Update()
+		return left, root
+	}
+}
+
+// Same as splitLess, but copies the nodes it changes instead of modifying them
+func splitLessCopy[T any](root *fhqTreapNode[T], key T, cmp func(a, b T) int, counter *stats.Counter) (*fhqTreapNode[T], *fhqTreapNode[T]) {
+	counter.Split()
+	if root == nil {
+		return nil, nil
+	}
+	root = root.clone()
+	if cmp(root.value, key) < 0 {
+		left, right := splitLessCopy(root.right, key, cmp, counter)
+		root.right = left
+		root.Update()
+		return root, right
+	} else {
+		left, right := splitLessCopy(root.left, key, cmp, counter)
+		root.left = right
+		root.Update()
+		return left, root
+	}
+}
+
+// Insert returns a new version holding value in expected O(log n). In unique
+// mode, p itself is returned when value is already present.
+func (p *Persistent[T]) Insert(value T) *Persistent[T] {
+	left, right := splitCopy(p.root, value, p.cmp, p.counter)
+	if p.unique {
+		var mid *fhqTreapNode[T]
+		left, mid = splitLessCopy(left, value, p.cmp, p.counter)
+		if mid != nil {
+			return p
+		}
+	}
+	root := mergeCopy(mergeCopy(left, newFHQTreapNode(value), p.counter), right, p.counter)
+	return &Persistent[T]{root: root, cmp: p.cmp, options: p.options, counter: p.counter}
+}
+
+// Delete returns a new version without one copy of value in expected
+// O(log n). p itself is returned when value is absent.
+func (p *Persistent[T]) Delete(value T) *Persistent[T] {
+	left, right := splitCopy(p.root, value, p.cmp, p.counter)
+	left, mid := splitLessCopy(left, value, p.cmp, p.counter)
+	if mid == nil {
+		return p
+	}
+	mid = mergeCopy(mid.left, mid.right, p.counter)
+	root := mergeCopy(mergeCopy(left, mid, p.counter), right, p.counter)
+	return &Persistent[T]{root: root, cmp: p.cmp, options: p.options, counter: p.counter}
+}
+
+func (p *Persistent[T]) Contains(value T) bool {
+	return search(p.root, value, p.cmp) != nil
+}
+
+func (p *Persistent[T]) Size() uint {
+	if p.root == nil {
+		return 0
+	}
+	return p.root.size
+}
+
+func (p *Persistent[T]) Empty() bool {
+	return p.root == nil
+}
+
+func (p *Persistent[T]) At(k uint) (T, error) {
+	result := At(p.root, k)
+	if result == nil {
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
+	}
+	return result.value, nil
+}
+
+func (p *Persistent[T]) Index(value T) uint {
+	return index(p.root, value, p.cmp)
+}
+
+func (p *Persistent[T]) Predecessor(value T) (T, error) {
+	result := predecessor(p.root, value, p.cmp)
+	if result == nil {
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
+	}
+	return result.value, nil
+}
+
+func (p *Persistent[T]) Successor(value T) (T, error) {
+	result := successor(p.root, value, p.cmp)
+	if result == nil {
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
+	}
+	return result.value, nil
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (p *Persistent[T]) Floor(value T) (T, bool) {
+	result := floor(p.root, value, p.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (p *Persistent[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(p.root, value, p.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Min returns the smallest value.
+func (p *Persistent[T]) Min() (T, bool) {
+	result := minimum(p.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (p *Persistent[T]) Max() (T, bool) {
+	result := maximum(p.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Count returns the number of copies of value.
+func (p *Persistent[T]) Count(value T) uint {
+	return upperIndex(p.root, value, p.cmp) - index(p.root, value, p.cmp)
+}
@@ -0,0 +1,39 @@
+package fhq
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yanglinshu/bstrees/v2/internal/render"
+)
+
+func describe[T any](root *fhqTreapNode[T]) *render.Node {
+	if root == nil {
+		return nil
+	}
+	n := &render.Node{Value: fmt.Sprint(root.value), Meta: fmt.Sprintf("weight=%d", root.weight)}
+	n.Left = describe(root.left)
+	n.Right = describe(root.right)
+	return n
+}
+
+// WriteDOT writes the structure of t as a Graphviz digraph, each node being
+// labeled with its weight.
+func (t *FHQTreap[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, describe(t.root))
+}
+
+// String draws the structure of t as text, see WriteDOT.
+func (t *FHQTreap[T]) String() string {
+	return render.String(describe(t.root))
+}
+
+// WriteDOT writes the structure of the version p, see FHQTreap.WriteDOT.
+func (p *Persistent[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, describe(p.root))
+}
+
+// String draws the structure of the version p as text, see WriteDOT.
+func (p *Persistent[T]) String() string {
+	return render.String(describe(p.root))
+}
@@ -0,0 +1,28 @@
+package fhq
+
+// SplitAt moves the values less than value to left and the others to right
+// in expected O(log n). t is left empty.
+func (t *FHQTreap[T]) SplitAt(value T) (left, right *FHQTreap[T]) {
+	l, r := splitLess(t.root, value, t.cmp, t.counter)
+	t.root = nil
+	return &FHQTreap[T]{root: l, cmp: t.cmp, options: t.options, counter: t.counter}, &FHQTreap[T]{root: r, cmp: t.cmp, options: t.options, counter: t.counter}
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// expected O(log n). t is left empty.
+func (t *FHQTreap[T]) SplitRank(k uint) (left, right *FHQTreap[T]) {
+	l, r := splitSize(t.root, k, t.counter)
+	t.root = nil
+	return &FHQTreap[T]{root: l, cmp: t.cmp, options: t.options, counter: t.counter}, &FHQTreap[T]{root: r, cmp: t.cmp, options: t.options, counter: t.counter}
+}
+
+// Join moves the values of left and right to a new tree in expected
+// O(log n). Every value of left must be less than every value of right, or
+// equal to it if the trees are not sets, which is not checked. The new tree
+// is ordered like left, and left and right are left empty.
+func Join[T any](left, right *FHQTreap[T]) *FHQTreap[T] {
+	result := &FHQTreap[T]{root: merge(left.root, right.root, left.counter), cmp: left.cmp, options: left.options, counter: left.counter}
+	left.root = nil
+	right.root = nil
+	return result
+}
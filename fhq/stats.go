@@ -0,0 +1,26 @@
+package fhq
+
+import "github.com/yanglinshu/bstrees/v2"
+
+// Stats returns the operations performed on t since it has been created or
+// since the last ResetStats. The trees made from t, such as the trees it is
+// split into, add to the same counts. Operations are only counted when the
+// module is built with the bstrees_stats tag, and cost nothing otherwise.
+func (t *FHQTreap[T]) Stats() bstrees.Stats {
+	return t.counter.Stats()
+}
+
+func (t *FHQTreap[T]) ResetStats() {
+	t.counter.Reset()
+}
+
+// Stats returns the operations performed on p and on all the versions it
+// shares its history with, since the first of them has been created or since
+// the last ResetStats, see FHQTreap.Stats.
+func (p *Persistent[T]) Stats() bstrees.Stats {
+	return p.counter.Stats()
+}
+
+func (p *Persistent[T]) ResetStats() {
+	p.counter.Reset()
+}
@@ -0,0 +1,137 @@
+// Package codec holds the serialized form shared by the trees of this module.
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// Tree is either the values of a tree in ascending order, or its nodes in
+// pre-order along with their balancing data.
+type Tree[T any] struct {
+	Values []T       `json:"values,omitempty"`
+	Nodes  []Node[T] `json:"nodes,omitempty"`
+}
+
+// Node is a serialized node, whose children follow it in pre-order. Each tree
+// only fills the balancing data it keeps.
+type Node[T any] struct {
+	Value   T    `json:"value"`
+	Left    bool `json:"left,omitempty"`    // Whether the node has a left child
+	Right   bool `json:"right,omitempty"`   // Whether the node has a right child
+	Height  int  `json:"height,omitempty"`  // AVL tree
+	Red     bool `json:"red,omitempty"`     // Red-black tree
+	Level   uint `json:"level,omitempty"`   // Anderson tree
+	Weight  uint `json:"weight,omitempty"`  // Treap and FHQ treap
+	Count   uint `json:"count,omitempty"`   // Splay
+	Deleted bool `json:"deleted,omitempty"` // Scapegoat tree
+}
+
+func MarshalBinary[T any](tree *Tree[T]) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(tree); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func UnmarshalBinary[T any](data []byte) (*Tree[T], error) {
+	tree := new(Tree[T])
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func MarshalJSON[T any](tree *Tree[T]) ([]byte, error) {
+	return json.Marshal(tree)
+}
+
+func UnmarshalJSON[T any](data []byte) (*Tree[T], error) {
+	tree := new(Tree[T])
+	if err := json.Unmarshal(data, tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Sorted reports whether values are in ascending order, strictly if unique is
+// set.
+func Sorted[T any](values []T, cmp func(a, b T) int, unique bool) bool {
+	for i := 1; i < len(values); i++ {
+		if c := cmp(values[i-1], values[i]); c > 0 || c == 0 && unique {
+			return false
+		}
+	}
+	return true
+}
+
+// Unique drops the duplicates of sorted values, values is returned as is if
+// there is none.
+func Unique[T any](values []T, cmp func(a, b T) int) []T {
+	for i := 1; i < len(values); i++ {
+		if cmp(values[i-1], values[i]) == 0 {
+			result := append(make([]T, 0, len(values)-1), values[:i]...)
+			for _, value := range values[i+1:] {
+				if cmp(result[len(result)-1], value) != 0 {
+					result = append(result, value)
+				}
+			}
+			return result
+		}
+	}
+	return values
+}
+
+// Corrupted reports that a decoded tree has failed its validation with err.
+func Corrupted(err error) error {
+	return fmt.Errorf("%w: %v", bstrees.ErrDataIsCorrupted, err)
+}
+
+// Build rebuilds a tree from its nodes, calling node on every node once its
+// children have been built, a missing child being the zero N. Returns
+// bstrees.ErrDataIsCorrupted unless nodes make up exactly one tree.
+func Build[T, N any](nodes []Node[T], node func(n *Node[T], left, right N) N) (N, error) {
+	b := builder[T, N]{nodes: nodes, node: node}
+	var root N
+	if len(nodes) == 0 {
+		return root, nil
+	}
+	root, ok := b.build()
+	if !ok || b.next != len(nodes) {
+		return root, bstrees.ErrDataIsCorrupted
+	}
+	return root, nil
+}
+
+type builder[T, N any] struct {
+	nodes []Node[T]
+	next  int
+	node  func(n *Node[T], left, right N) N
+}
+
+func (b *builder[T, N]) build() (N, bool) {
+	var left, right N
+	if b.next == len(b.nodes) {
+		return left, false
+	}
+	n := &b.nodes[b.next]
+	b.next++
+	if n.Left {
+		var ok bool
+		if left, ok = b.build(); !ok {
+			return left, false
+		}
+	}
+	if n.Right {
+		var ok bool
+		if right, ok = b.build(); !ok {
+			return right, false
+		}
+	}
+	return b.node(n, left, right), true
+}
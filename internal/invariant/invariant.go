@@ -0,0 +1,40 @@
+// Package invariant holds the checks shared by the Validate methods of the
+// trees of this module.
+package invariant
+
+import (
+	"fmt"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// Errorf reports that the node holding value breaks the rule described by
+// format.
+func Errorf(value any, format string, args ...any) error {
+	return &bstrees.InvariantError{Value: value, Rule: fmt.Sprintf(format, args...)}
+}
+
+// Order checks that the values of a tree are visited in ascending order,
+// strictly if unique is set.
+type Order[T any] struct {
+	cmp    func(a, b T) int
+	unique bool
+	last   *T
+}
+
+func NewOrder[T any](cmp func(a, b T) int, unique bool) *Order[T] {
+	return &Order[T]{cmp: cmp, unique: unique}
+}
+
+// Next checks value against the value visited before it.
+func (o *Order[T]) Next(value T) error {
+	if o.last != nil {
+		if c := o.cmp(*o.last, value); c > 0 {
+			return Errorf(value, "is less than the value %v before it", *o.last)
+		} else if c == 0 && o.unique {
+			return Errorf(value, "is equal to the value before it in a set")
+		}
+	}
+	o.last = &value
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build go1.23
+
+package ordmap
+
+import "iter"
+
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return m.Ascend
+}
+
+func (m *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return m.Descend
+}
+
+func (m *Map[K, V]) Range(greaterOrEqual, lessThan K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.AscendRange(greaterOrEqual, lessThan, yield)
+	}
+}
+
+func (m *Map[K, V]) RangeBackward(lessOrEqual, greaterThan K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.DescendRange(lessOrEqual, greaterThan, yield)
+	}
+}
@@ -0,0 +1,25 @@
+package ordmap
+
+func (m *Map[K, V]) Ascend(fn func(key K, value V) bool) {
+	m.tree.Ascend(func(e Entry[K, V]) bool {
+		return fn(e.Key, e.Value)
+	})
+}
+
+func (m *Map[K, V]) Descend(fn func(key K, value V) bool) {
+	m.tree.Descend(func(e Entry[K, V]) bool {
+		return fn(e.Key, e.Value)
+	})
+}
+
+func (m *Map[K, V]) AscendRange(greaterOrEqual, lessThan K, fn func(key K, value V) bool) {
+	m.tree.AscendRange(Entry[K, V]{Key: greaterOrEqual}, Entry[K, V]{Key: lessThan}, func(e Entry[K, V]) bool {
+		return fn(e.Key, e.Value)
+	})
+}
+
+func (m *Map[K, V]) DescendRange(lessOrEqual, greaterThan K, fn func(key K, value V) bool) {
+	m.tree.DescendRange(Entry[K, V]{Key: lessOrEqual}, Entry[K, V]{Key: greaterThan}, func(e Entry[K, V]) bool {
+		return fn(e.Key, e.Value)
+	})
+}
@@ -0,0 +1,139 @@
+// Package ordmap implements the ordered maps of this module once, on top of
+// any of its trees.
+package ordmap
+
+import "github.com/yanglinshu/bstrees/v2"
+
+type Entry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// Tree is a set of entries ordered by key, which each package adapts its tree
+// to.
+type Tree[K, V any] interface {
+	bstrees.Tree[Entry[K, V]]
+	Search(key K) *Entry[K, V] // Entry stored for key, or nil
+	// Entry stored for key, inserting one with a zero value if key is
+	// absent, and whether key was already present, in a single descent
+	Upsert(key K) (*Entry[K, V], bool)
+	// Deletes the entry stored for key, and reports whether there was one,
+	// in a single descent
+	Remove(key K) bool
+	Floor(value Entry[K, V]) (Entry[K, V], bool)
+	Ceiling(value Entry[K, V]) (Entry[K, V], bool)
+	Min() (Entry[K, V], bool)
+	Max() (Entry[K, V], bool)
+	PopMin() (Entry[K, V], bool)
+	PopMax() (Entry[K, V], bool)
+	Ascend(fn func(value Entry[K, V]) bool)
+	Descend(fn func(value Entry[K, V]) bool)
+	AscendRange(greaterOrEqual, lessThan Entry[K, V], fn func(value Entry[K, V]) bool)
+	DescendRange(lessOrEqual, greaterThan Entry[K, V], fn func(value Entry[K, V]) bool)
+}
+
+// Map is an ordered map stored in a Tree, with at most one value per key.
+type Map[K, V any] struct {
+	tree Tree[K, V]
+}
+
+func New[K, V any](tree Tree[K, V]) Map[K, V] {
+	return Map[K, V]{tree: tree}
+}
+
+func (m *Map[K, V]) Put(key K, value V) {
+	entry, _ := m.tree.Upsert(key)
+	entry.Value = value
+}
+
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if entry := m.tree.Search(key); entry != nil {
+		return entry.Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *Map[K, V]) Delete(key K) bool {
+	return m.tree.Remove(key)
+}
+
+func (m *Map[K, V]) GetOrInsert(key K, value V) (V, bool) {
+	entry, ok := m.tree.Upsert(key)
+	if !ok {
+		entry.Value = value
+	}
+	return entry.Value, ok
+}
+
+// Update sets the value of key to fn(value, true) if key is present, or to
+// fn(zero, false) otherwise.
+func (m *Map[K, V]) Update(key K, fn func(value V, ok bool) V) {
+	entry, ok := m.tree.Upsert(key)
+	entry.Value = fn(entry.Value, ok)
+}
+
+func (m *Map[K, V]) Contains(key K) bool {
+	return m.tree.Search(key) != nil
+}
+
+func (m *Map[K, V]) Size() uint {
+	return m.tree.Size()
+}
+
+func (m *Map[K, V]) Empty() bool {
+	return m.tree.Empty()
+}
+
+func (m *Map[K, V]) Clear() {
+	m.tree.Clear()
+}
+
+func (m *Map[K, V]) At(k uint) (K, V, error) {
+	result, err := m.tree.At(k)
+	return result.Key, result.Value, err
+}
+
+func (m *Map[K, V]) Index(key K) uint {
+	return m.tree.Index(Entry[K, V]{Key: key})
+}
+
+func (m *Map[K, V]) Predecessor(key K) (K, V, error) {
+	result, err := m.tree.Predecessor(Entry[K, V]{Key: key})
+	return result.Key, result.Value, err
+}
+
+func (m *Map[K, V]) Successor(key K) (K, V, error) {
+	result, err := m.tree.Successor(Entry[K, V]{Key: key})
+	return result.Key, result.Value, err
+}
+
+func (m *Map[K, V]) Floor(key K) (K, V, bool) {
+	result, ok := m.tree.Floor(Entry[K, V]{Key: key})
+	return result.Key, result.Value, ok
+}
+
+func (m *Map[K, V]) Ceiling(key K) (K, V, bool) {
+	result, ok := m.tree.Ceiling(Entry[K, V]{Key: key})
+	return result.Key, result.Value, ok
+}
+
+func (m *Map[K, V]) Min() (K, V, bool) {
+	result, ok := m.tree.Min()
+	return result.Key, result.Value, ok
+}
+
+func (m *Map[K, V]) Max() (K, V, bool) {
+	result, ok := m.tree.Max()
+	return result.Key, result.Value, ok
+}
+
+func (m *Map[K, V]) PopMin() (K, V, bool) {
+	result, ok := m.tree.PopMin()
+	return result.Key, result.Value, ok
+}
+
+func (m *Map[K, V]) PopMax() (K, V, bool) {
+	result, ok := m.tree.PopMax()
+	return result.Key, result.Value, ok
+}
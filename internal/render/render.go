@@ -0,0 +1,101 @@
+// Package render draws the trees of this module, in Graphviz DOT or as text.
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Node is a node to draw, built by each tree from its own nodes.
+type Node struct {
+	Value  string
+	Meta   string // Balancing data, such as "height=2"
+	Color  string // DOT color of the node, if any
+	Dashed bool   // Whether the node is drawn dashed, e.g. a deleted node
+	Left   *Node
+	Right  *Node
+}
+
+// WriteDOT writes root as a Graphviz digraph. A missing child is drawn as an
+// invisible node when the other one exists, so that left and right children
+// are told apart.
+func WriteDOT(w io.Writer, root *Node) error {
+	b := bufio.NewWriter(w)
+	b.WriteString("digraph {\n\tnode [shape=circle];\n")
+	if root != nil {
+		writeDOT(b, root, 0)
+	}
+	b.WriteString("}\n")
+	return b.Flush()
+}
+
+// Write the nodes of root numbered in pre-order from id, returns the next id
+func writeDOT(b *bufio.Writer, root *Node, id int) int {
+	label := root.Value
+	if root.Meta != "" {
+		label += "\n" + root.Meta
+	}
+	attrs := []string{"label=" + strconv.Quote(label)}
+	if root.Color != "" {
+		attrs = append(attrs, "color="+root.Color)
+	}
+	if root.Dashed {
+		attrs = append(attrs, "style=dashed")
+	}
+	fmt.Fprintf(b, "\tn%d [%s];\n", id, strings.Join(attrs, ", "))
+	next := id + 1
+	for _, child := range []*Node{root.Left, root.Right} {
+		if child != nil {
+			fmt.Fprintf(b, "\tn%d -> n%d;\n", id, next)
+			next = writeDOT(b, child, next)
+		} else if root.Left != nil || root.Right != nil {
+			fmt.Fprintf(b, "\tn%d [style=invis];\n\tn%d -> n%d [style=invis];\n", next, id, next)
+			next++
+		}
+	}
+	return next
+}
+
+// String draws root as text, with the left child of each node above its
+// right child:
+//
+//	2 (height=1)
+//	├── 1 (height=0)
+//	└── 3 (height=0)
+func String(root *Node) string {
+	if root == nil {
+		return "<empty>"
+	}
+	var b strings.Builder
+	b.WriteString(root.text() + "\n")
+	writeChildren(&b, root, "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeChildren(b *strings.Builder, root *Node, prefix string) {
+	if root.Left == nil && root.Right == nil {
+		return
+	}
+	for i, child := range []*Node{root.Left, root.Right} {
+		branch, indent := "├── ", "│   "
+		if i == 1 {
+			branch, indent = "└── ", "    "
+		}
+		if child == nil {
+			b.WriteString(prefix + branch + "<nil>\n")
+			continue
+		}
+		b.WriteString(prefix + branch + child.text() + "\n")
+		writeChildren(b, child, prefix+indent)
+	}
+}
+
+func (n *Node) text() string {
+	if n.Meta == "" {
+		return n.Value
+	}
+	return n.Value + " (" + n.Meta + ")"
+}
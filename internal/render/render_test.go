@@ -0,0 +1,46 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	root := &Node{Value: "2", Meta: "level=2",
+		Left:  &Node{Value: "1", Left: &Node{Value: "0"}},
+		Right: &Node{Value: "3", Meta: "level=1"},
+	}
+	want := `2 (level=2)
+├── 1
+│   ├── 0
+│   └── <nil>
+└── 3 (level=1)`
+	if got := String(root); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+	if got := String(nil); got != "<empty>" {
+		t.Errorf("String(nil) = %q, want %q", got, "<empty>")
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	root := &Node{Value: "2", Meta: "red", Color: "red",
+		Right: &Node{Value: "3", Dashed: true},
+	}
+	want := `digraph {
+	node [shape=circle];
+	n0 [label="2\nred", color=red];
+	n1 [style=invis];
+	n0 -> n1 [style=invis];
+	n0 -> n2;
+	n2 [label="3", style=dashed];
+}
+`
+	var b strings.Builder
+	if err := WriteDOT(&b, root); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("WriteDOT() = \n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !bstrees_stats
+
+package stats
+
+import "github.com/yanglinshu/bstrees/v2"
+
+// Enabled reports whether the operations are counted.
+const Enabled = false
+
+// Counter counts nothing, its methods being empty so that calls to them are
+// inlined away.
+type Counter struct{}
+
+// Compare returns cmp as is.
+func Compare[T any](c *Counter, cmp func(a, b T) int) func(a, b T) int {
+	return cmp
+}
+
+func (c *Counter) Rotation()       {}
+func (c *Counter) DoubleRotation() {}
+func (c *Counter) Zig()            {}
+func (c *Counter) ZigZig()         {}
+func (c *Counter) ZigZag()         {}
+func (c *Counter) Skew()           {}
+func (c *Counter) LevelSplit()     {}
+func (c *Counter) Split()          {}
+func (c *Counter) Merge()          {}
+func (c *Counter) Rebuild(n int)   {}
+
+func (c *Counter) Stats() bstrees.Stats {
+	return bstrees.Stats{}
+}
+
+func (c *Counter) Reset() {}
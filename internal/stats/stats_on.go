@@ -0,0 +1,78 @@
+//go:build bstrees_stats
+
+// Package stats counts the operations of the trees of this module when built
+// with the bstrees_stats tag, and compiles to nothing otherwise.
+package stats
+
+import (
+	"sync/atomic"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+// Enabled reports whether the operations are counted.
+const Enabled = true
+
+// Counter holds the counts of a tree, and is safe for concurrent use.
+type Counter struct {
+	comparisons     atomic.Uint64
+	rotations       atomic.Uint64
+	doubleRotations atomic.Uint64
+	zigs            atomic.Uint64
+	zigZigs         atomic.Uint64
+	zigZags         atomic.Uint64
+	skews           atomic.Uint64
+	levelSplits     atomic.Uint64
+	splits          atomic.Uint64
+	merges          atomic.Uint64
+	rebuilds        atomic.Uint64
+	rebuiltNodes    atomic.Uint64
+}
+
+// Compare returns cmp counting its calls.
+func Compare[T any](c *Counter, cmp func(a, b T) int) func(a, b T) int {
+	return func(a, b T) int {
+		c.comparisons.Add(1)
+		return cmp(a, b)
+	}
+}
+
+func (c *Counter) Rotation()       { c.rotations.Add(1) }
+func (c *Counter) DoubleRotation() { c.doubleRotations.Add(1) }
+func (c *Counter) Zig()            { c.zigs.Add(1) }
+func (c *Counter) ZigZig()         { c.zigZigs.Add(1) }
+func (c *Counter) ZigZag()         { c.zigZags.Add(1) }
+func (c *Counter) Skew()           { c.skews.Add(1) }
+func (c *Counter) LevelSplit()     { c.levelSplits.Add(1) }
+func (c *Counter) Split()          { c.splits.Add(1) }
+func (c *Counter) Merge()          { c.merges.Add(1) }
+
+// Rebuild counts a subtree of n nodes being rebuilt.
+func (c *Counter) Rebuild(n int) {
+	c.rebuilds.Add(1)
+	c.rebuiltNodes.Add(uint64(n))
+}
+
+func (c *Counter) Stats() bstrees.Stats {
+	return bstrees.Stats{
+		Comparisons:     c.comparisons.Load(),
+		Rotations:       c.rotations.Load(),
+		DoubleRotations: c.doubleRotations.Load(),
+		Zigs:            c.zigs.Load(),
+		ZigZigs:         c.zigZigs.Load(),
+		ZigZags:         c.zigZags.Load(),
+		Skews:           c.skews.Load(),
+		LevelSplits:     c.levelSplits.Load(),
+		Splits:          c.splits.Load(),
+		Merges:          c.merges.Load(),
+		Rebuilds:        c.rebuilds.Load(),
+		RebuiltNodes:    c.rebuiltNodes.Load(),
+	}
+}
+
+func (c *Counter) Reset() {
+	for _, n := range []*atomic.Uint64{&c.comparisons, &c.rotations, &c.doubleRotations, &c.zigs, &c.zigZigs,
+		&c.zigZags, &c.skews, &c.levelSplits, &c.splits, &c.merges, &c.rebuilds, &c.rebuiltNodes} {
+		n.Store(0)
+	}
+}
@@ -0,0 +1,28 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+)
+
+func TestCounter(t *testing.T) {
+	var c Counter
+	cmp := Compare(&c, bstrees.Compare[int])
+	if cmp(1, 2) >= 0 || cmp(2, 1) <= 0 || cmp(1, 1) != 0 {
+		t.Error("Compare does not preserve the comparison")
+	}
+	c.Rotation()
+	c.Rebuild(5)
+	want := bstrees.Stats{}
+	if Enabled {
+		want = bstrees.Stats{Comparisons: 3, Rotations: 1, Rebuilds: 1, RebuiltNodes: 5}
+	}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	c.Reset()
+	if got := c.Stats(); got != (bstrees.Stats{}) {
+		t.Errorf("Stats() = %+v after Reset, want zero", got)
+	}
+}
@@ -1,9 +1,11 @@
 package rb
 
-import "golang.org/x/exp/constraints"
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
 
-func singleRotate[T constraints.Ordered](root *rbTreeNode[T], direction bool) *rbTreeNode[T] {
-	save := root.child(!direction)
+func singleRotate[T any](root *rbTreeNode[T], direction bool, cow *copyOnWrite, counter *stats.Counter) *rbTreeNode[T] {
+	counter.Rotation()
+	root = root.mutable(cow)
+	save := root.mutableChild(!direction, cow)
 	root.setChild(!direction, save.child(direction))
 	save.setChild(direction, root)
 	root.Update()
@@ -13,7 +15,27 @@ func singleRotate[T constraints.Ordered](root *rbTreeNode[T], direction bool) *r
 	return save
 }
 
-func doubleRotate[T constraints.Ordered](root *rbTreeNode[T], direction bool) *rbTreeNode[T] {
-	root.setChild(!direction, singleRotate(root.child(!direction), !direction))
-	return singleRotate(root, direction)
+func doubleRotate[T any](root *rbTreeNode[T], direction bool, cow *copyOnWrite, counter *stats.Counter) *rbTreeNode[T] {
+	counter.DoubleRotation()
+	root = root.mutable(cow)
+	root.setChild(!direction, singleRotate(root.child(!direction), !direction, cow, counter))
+	return singleRotate(root, direction, cow, counter)
+}
+
+// Build a balanced tree from sorted values. Every level but the last one is
+// complete, so the nodes of an incomplete last level at redDepth are red and
+// the others are black.
+func fromSorted[T any](values []T, depth, redDepth int) *rbTreeNode[T] {
+	if len(values) == 0 {
+		return nil
+	}
+	mid := len(values) / 2
+	root := newRBTreeNode(values[mid], nil)
+	if depth != redDepth {
+		root.color = black
+	}
+	root.left = fromSorted(values[:mid], depth+1, redDepth)
+	root.right = fromSorted(values[mid+1:], depth+1, redDepth)
+	root.Update()
+	return root
 }
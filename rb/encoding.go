@@ -0,0 +1,107 @@
+package rb
+
+import (
+	"math/bits"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+)
+
+// Append the nodes of root in pre-order
+func encodeNodes[T any](root *rbTreeNode[T], nodes []codec.Node[T]) []codec.Node[T] {
+	if root == nil {
+		return nodes
+	}
+	nodes = append(nodes, codec.Node[T]{Value: root.value, Left: root.left != nil, Right: root.right != nil, Red: root.color == red})
+	nodes = encodeNodes(root.left, nodes)
+	return encodeNodes(root.right, nodes)
+}
+
+// Rebuild a node from its serialized form
+func decodeNode[T any](n *codec.Node[T], left, right *rbTreeNode[T]) *rbTreeNode[T] {
+	node := &rbTreeNode[T]{value: n.Value, left: left, right: right, color: black}
+	if n.Red {
+		node.color = red
+	}
+	node.Update()
+	return node
+}
+
+func (t *RBTree[T]) encode() *codec.Tree[T] {
+	tree := new(codec.Tree[T])
+	if t.shape {
+		tree.Nodes = encodeNodes(t.root, make([]codec.Node[T], 0, t.Size()))
+	} else {
+		tree.Values = make([]T, 0, t.Size())
+		ascend(t.root, func(value T) bool {
+			tree.Values = append(tree.Values, value)
+			return true
+		})
+	}
+	return tree
+}
+
+func (t *RBTree[T]) decode(tree *codec.Tree[T]) error {
+	if t.cmp == nil {
+		return bstrees.ErrTreeIsNotInitialized
+	}
+	if len(tree.Nodes) != 0 {
+		root, err := codec.Build(tree.Nodes, decodeNode[T])
+		if err != nil {
+			return err
+		}
+		if err := (&RBTree[T]{root: root, cmp: t.cmp, options: t.options, counter: t.counter}).Validate(); err != nil {
+			return codec.Corrupted(err)
+		}
+		t.root, t.cow = root, nil
+		return nil
+	}
+	if !codec.Sorted(tree.Values, t.cmp, false) {
+		return bstrees.ErrDataIsCorrupted
+	}
+	values := tree.Values
+	if t.unique {
+		values = codec.Unique(values, t.cmp)
+	}
+	t.root, t.cow = fromSorted(values, 0, bits.Len(uint(len(values)+1))-1), nil
+	return nil
+}
+
+// MarshalBinary encodes the values of t in ascending order, or its nodes with
+// their colors if t has been created with PreserveShape.
+func (t *RBTree[T]) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(t.encode())
+}
+
+// UnmarshalBinary replaces the values of t with the ones encoded by
+// MarshalBinary, in O(n) whichever the encoding. t must have been created by a
+// constructor, and keeps its own options.
+func (t *RBTree[T]) UnmarshalBinary(data []byte) error {
+	tree, err := codec.UnmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+// MarshalJSON encodes t like MarshalBinary, as a JSON object.
+func (t *RBTree[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(t.encode())
+}
+
+// UnmarshalJSON decodes t like UnmarshalBinary.
+func (t *RBTree[T]) UnmarshalJSON(data []byte) error {
+	tree, err := codec.UnmarshalJSON[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+func (t *RBTree[T]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+func (t *RBTree[T]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
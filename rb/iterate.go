@@ -0,0 +1,87 @@
+package rb
+
+// Iterate over root in order, stop when fn returns false.
+// Returns false if the iteration has been stopped.
+func ascend[T any](root *rbTreeNode[T], fn func(T) bool) bool {
+	if root == nil {
+		return true
+	}
+	if !ascend(root.left, fn) {
+		return false
+	}
+	if !fn(root.value) {
+		return false
+	}
+	return ascend(root.right, fn)
+}
+
+func descend[T any](root *rbTreeNode[T], fn func(T) bool) bool {
+	if root == nil {
+		return true
+	}
+	if !descend(root.right, fn) {
+		return false
+	}
+	if !fn(root.value) {
+		return false
+	}
+	return descend(root.left, fn)
+}
+
+// Iterate over values in [lo, hi) in order
+func ascendRange[T any](root *rbTreeNode[T], lo, hi T, fn func(T) bool, cmp func(a, b T) int) bool {
+	if root == nil {
+		return true
+	}
+	afterLo := cmp(root.value, lo) >= 0
+	beforeHi := cmp(root.value, hi) < 0
+	if afterLo && !ascendRange(root.left, lo, hi, fn, cmp) {
+		return false
+	}
+	if afterLo && beforeHi {
+		if !fn(root.value) {
+			return false
+		}
+	}
+	return !beforeHi || ascendRange(root.right, lo, hi, fn, cmp)
+}
+
+// Iterate over values in (lo, hi] in reverse order
+func descendRange[T any](root *rbTreeNode[T], hi, lo T, fn func(T) bool, cmp func(a, b T) int) bool {
+	if root == nil {
+		return true
+	}
+	afterLo := cmp(root.value, lo) > 0
+	beforeHi := cmp(root.value, hi) <= 0
+	if beforeHi && !descendRange(root.right, hi, lo, fn, cmp) {
+		return false
+	}
+	if afterLo && beforeHi {
+		if !fn(root.value) {
+			return false
+		}
+	}
+	return !afterLo || descendRange(root.left, hi, lo, fn, cmp)
+}
+
+// Ascend calls fn for every value in ascending order until fn returns false.
+func (t *RBTree[T]) Ascend(fn func(value T) bool) {
+	ascend(t.root, fn)
+}
+
+// Descend calls fn for every value in descending order until fn returns false.
+func (t *RBTree[T]) Descend(fn func(value T) bool) {
+	descend(t.root, fn)
+}
+
+// AscendRange calls fn for every value in [greaterOrEqual, lessThan) in
+// ascending order until fn returns false.
+func (t *RBTree[T]) AscendRange(greaterOrEqual, lessThan T, fn func(value T) bool) {
+	ascendRange(t.root, greaterOrEqual, lessThan, fn, t.cmp)
+}
+
+// DescendRange calls fn for every value in (greaterThan, lessOrEqual] in
+// descending order until fn returns false.
+func (t *RBTree[T]) DescendRange(lessOrEqual, greaterThan T, fn func(value T) bool) {
+	descendRange(t.root, lessOrEqual, greaterThan, fn, t.cmp)
+}
@@ -1,7 +1,5 @@
 package rb
 
-import "golang.org/x/exp/constraints"
-
 type rbColor bool
 
 const (
@@ -9,17 +7,51 @@ const (
 	black rbColor = false
 )
 
-type rbTreeNode[T constraints.Ordered] struct {
+// Nodes are shared between a tree and its snapshots. A tree only modifies the
+// nodes created with its own copyOnWrite, and copies the others first.
+type copyOnWrite struct {
+	_ byte // Gives every copyOnWrite a distinct address
+}
+
+type rbTreeNode[T any] struct {
 	value T
 	left  *rbTreeNode[T]
 	right *rbTreeNode[T]
 	color rbColor
 	size  uint // Size of subtree, unnecessary if you don't need kth element
+	cow   *copyOnWrite
 	// Father *RBNode[T] // Not necessary, but easier to implement
 }
 
-func newRBTreeNode[T constraints.Ordered](value T) *rbTreeNode[T] {
-	return &rbTreeNode[T]{value: value, left: nil, right: nil, color: red, size: 1}
+func newRBTreeNode[T any](value T, cow *copyOnWrite) *rbTreeNode[T] {
+	return &rbTreeNode[T]{value: value, left: nil, right: nil, color: red, size: 1, cow: cow}
+}
+
+// Token for a tree taking over nodes of the tree owning cow. A tree that has
+// never been snapshotted shares no node, and keeps a nil token.
+func (cow *copyOnWrite) fork() *copyOnWrite {
+	if cow == nil {
+		return nil
+	}
+	return new(copyOnWrite)
+}
+
+// Token for a tree made of the nodes of the trees owning a and b
+func joinCOW(a, b *copyOnWrite) *copyOnWrite {
+	if a == nil {
+		return b.fork()
+	}
+	return a.fork()
+}
+
+// Returns n if it can be modified by the tree owning cow, or a copy of n
+func (n *rbTreeNode[T]) mutable(cow *copyOnWrite) *rbTreeNode[T] {
+	if n.cow == cow {
+		return n
+	}
+	clone := *n
+	clone.cow = cow
+	return &clone
 }
 
 func (n *rbTreeNode[T]) Update() {
@@ -53,6 +85,17 @@ func (n *rbTreeNode[T]) setChild(direction bool, child *rbTreeNode[T]) {
 	// n.Update()
 }
 
-func isRed[T constraints.Ordered](root *rbTreeNode[T]) bool {
+// Makes the child of n towards direction mutable, see mutable. n must be
+// mutable.
+func (n *rbTreeNode[T]) mutableChild(direction bool, cow *copyOnWrite) *rbTreeNode[T] {
+	child := n.child(direction)
+	if child != nil {
+		child = child.mutable(cow)
+		n.setChild(direction, child)
+	}
+	return child
+}
+
+func isRed[T any](root *rbTreeNode[T]) bool {
 	return root != nil && root.red()
 }
@@ -1,19 +1,54 @@
 package rb
 
 import (
+	"math/bits"
+
 	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
 	"golang.org/x/exp/constraints"
 )
 
-type RBTree[T constraints.Ordered] struct {
+var _ bstrees.Tree[int] = (*RBTree[int])(nil)
+
+// RBTree must be created by a constructor such as New or NewFunc.
+type RBTree[T any] struct {
 	root *rbTreeNode[T]
+	cmp  func(a, b T) int
+	options
+	cow     *copyOnWrite
+	counter *stats.Counter // Shared with the trees made from this one
+}
+
+func New[T constraints.Ordered](opts ...Option) *RBTree[T] {
+	return NewFunc(bstrees.Compare[T], opts...)
+}
+
+// NewFunc creates a tree ordered by cmp, which returns a negative number when
+// a < b, a positive number when a > b and zero when a == b.
+func NewFunc[T any](cmp func(a, b T) int, opts ...Option) *RBTree[T] {
+	counter := new(stats.Counter)
+	return &RBTree[T]{root: nil, cmp: stats.Compare(counter, cmp), options: newOptions(opts), counter: counter}
 }
 
-func New[T constraints.Ordered]() *RBTree[T] {
-	return &RBTree[T]{root: nil}
+// FromSorted builds a tree from values sorted in ascending order in O(n).
+// The order of values is not checked.
+func FromSorted[T constraints.Ordered](values []T, opts ...Option) *RBTree[T] {
+	return FromSortedFunc(bstrees.Compare[T], values, opts...)
 }
 
-func at[T constraints.Ordered](root *rbTreeNode[T], k uint) *rbTreeNode[T] {
+// FromSortedFunc builds a tree ordered by cmp from values sorted by cmp in
+// O(n), see NewFunc and FromSorted.
+func FromSortedFunc[T any](cmp func(a, b T) int, values []T, opts ...Option) *RBTree[T] {
+	t := NewFunc(cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
+	}
+	t.root = fromSorted(values, 0, bits.Len(uint(len(values)+1))-1)
+	return t
+}
+
+func at[T any](root *rbTreeNode[T], k uint) *rbTreeNode[T] {
 	for root != nil {
 		leftSize := uint(0)
 		if root.left != nil {
@@ -32,14 +67,20 @@ func at[T constraints.Ordered](root *rbTreeNode[T], k uint) *rbTreeNode[T] {
 }
 
 // https://archive.ph/EJTsz, Eternally Confuzzled's Blog
-func insert[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T] {
+// Returns the new root, the node holding value and whether value has been
+// inserted, which is false only if unique is set and value is already present.
+func insert[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int, unique bool, cow *copyOnWrite, counter *stats.Counter) (*rbTreeNode[T], *rbTreeNode[T], bool) {
+	var node *rbTreeNode[T]
+	inserted := true
 	if root == nil {
-		root = newRBTreeNode(value)
+		root = newRBTreeNode(value, cow)
+		node = root
 	} else {
-		superRoot := newRBTreeNode(T(rune(0))) // Head in Eternally Confuzzled's paper
-		superRoot.right = root
+		var zero T
+		superRoot := newRBTreeNode(zero, cow) // Head in Eternally Confuzzled's paper
+		superRoot.right = root.mutable(cow)
 
-		var child *rbTreeNode[T] = root                 // Q in Eternally Confuzzled's paper
+		var child *rbTreeNode[T] = superRoot.right      // Q in Eternally Confuzzled's paper
 		var parent *rbTreeNode[T] = nil                 // P in Eternally Confuzzled's paper
 		var grandParent *rbTreeNode[T] = nil            // G in Eternally Confuzzled's paper
 		var greatGrandParent *rbTreeNode[T] = superRoot // T in Eternally Confuzzled's paper
@@ -51,64 +92,65 @@ func insert[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T]
 		for ok := false; !ok; {
 			if child == nil {
 				// Insert new node at the bottom
-				child = newRBTreeNode(value)
+				child = newRBTreeNode(value, cow)
 				parent.setChild(direction, child)
+				node = child
 				ok = true
-			} else {
-				// Update size
-				child.size += 1
-				if isRed(child.left) && isRed(child.right) {
-					// Color flip
-					child.color = red
-					child.left.color = black
-					child.right.color = black
+				// Update the sizes of the nodes above it, which rotations
+				// done on the way down computed without it
+				for p := superRoot.right; p != child; p = p.child(cmp(p.value, value) < 0) {
+					p.size += 1
 				}
+			} else if unique && cmp(child.value, value) == 0 {
+				node = child
+				inserted = false
+				break
+			} else if isRed(child.left) && isRed(child.right) {
+				// Color flip
+				child.color = red
+				child.mutableChild(false, cow).color = black
+				child.mutableChild(true, cow).color = black
 			}
 
 			if isRed(child) && isRed(parent) {
 				// Fix red violation
 				direction2 := greatGrandParent.right == grandParent
 				if child == parent.child(lastDirection) {
-					greatGrandParent.setChild(direction2, singleRotate(grandParent, !lastDirection))
-					// When performing a single rotation to grandparent, child is not affected.
-					// So when grandparent(old) and parent(old) is updated, there are all +1ed.
+					greatGrandParent.setChild(direction2, singleRotate(grandParent, !lastDirection, cow, counter))
 				} else {
-					greatGrandParent.setChild(direction2, doubleRotate(grandParent, !lastDirection))
-					if !ok {
-						// When performing a double rotation to grandparent, child is affected.
-						// So we need to update child(now grandParent)'s size. But there is no need we insert is done.
-						greatGrandParent.child(direction2).size += 1
-					}
+					greatGrandParent.setChild(direction2, doubleRotate(grandParent, !lastDirection, cow, counter))
 				}
 			}
 
 			lastDirection = direction
-			direction = child.value < value
+			direction = cmp(child.value, value) < 0
 			if grandParent != nil {
 				greatGrandParent = grandParent
 			}
 
 			grandParent = parent
 			parent = child
-			child = child.child(direction)
+			child = child.mutableChild(direction, cow)
 		}
 
 		// Update root
 		root = superRoot.right
 	}
 	root.color = black
-	return root
+	return root, node, inserted
 }
 
-func (t *RBTree[T]) Insert(value T) {
-	t.root = insert(t.root, value)
+func (t *RBTree[T]) Insert(value T) bool {
+	var inserted bool
+	t.root, _, inserted = insert(t.root, value, t.cmp, t.unique, t.cow, t.counter)
+	return inserted
 }
 
-func search[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T] {
+func search[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int) *rbTreeNode[T] {
 	for root != nil {
-		if root.value == value {
+		if c := cmp(root.value, value); c == 0 {
 			return root
-		} else if root.value < value {
+		} else if c < 0 {
 			root = root.right
 		} else {
 			root = root.left
@@ -118,14 +160,28 @@ func search[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T]
 }
 
 func (t *RBTree[T]) Contains(value T) bool {
-	return search(t.root, value) != nil
+	return search(t.root, value, t.cmp) != nil
 }
 
-func delete[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T] {
-	if root == nil || search(root, value) == nil {
-		return root
+// Delete one copy of value from root, returns the new root and whether value
+// has been deleted
+func delete[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int, cow *copyOnWrite, counter *stats.Counter) (*rbTreeNode[T], bool) {
+	if root == nil || search(root, value, cmp) == nil {
+		return root, false
 	}
-	superRoot := newRBTreeNode(T(rune(0))) // Head in Eternally Confuzzled's paper
+	root, _ = remove(root, func(node *rbTreeNode[T]) int {
+		return cmp(node.value, value)
+	}, cow, counter)
+	return root, true
+}
+
+// Remove the node located by where from a non-empty tree, where(node) tells
+// whether the node to remove is on the right (< 0), on the left (> 0) or is
+// node itself (0). If where never returns 0, the last node on the path is
+// removed. Returns the new root and the removed value.
+func remove[T any](root *rbTreeNode[T], where func(node *rbTreeNode[T]) int, cow *copyOnWrite, counter *stats.Counter) (*rbTreeNode[T], T) {
+	var zero T
+	superRoot := newRBTreeNode(zero, cow) // Head in Eternally Confuzzled's paper
 	superRoot.right = root
 
 	var child *rbTreeNode[T] = superRoot // Q in Eternally Confuzzled's paper
@@ -140,21 +196,22 @@ func delete[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T]
 
 		grandParent = parent
 		parent = child
-		child = child.child(direction)
-		direction = child.value < value
+		child = child.mutableChild(direction, cow)
+		c := where(child)
+		direction = c < 0
 
 		// Update size
 		child.size -= 1
 
 		// Save the target node
-		if child.value == value {
+		if c == 0 {
 			target = child
 		}
 
 		// Push the red node down
 		if !isRed(child) && !isRed(child.child(direction)) {
 			if isRed(child.child(!direction)) {
-				parent.setChild(lastDirection, singleRotate(child, direction))
+				parent.setChild(lastDirection, singleRotate(child, direction, cow, counter))
 				parent = parent.child(lastDirection)
 
 				// When performing a single rotation to child, child is affected.
@@ -162,7 +219,7 @@ func delete[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T]
 				child.size -= 1
 				parent.Update()
 			} else if !isRed(child.child(!direction)) {
-				sibling := parent.child(!lastDirection)
+				sibling := parent.mutableChild(!lastDirection, cow)
 				if sibling != nil {
 					if !isRed(sibling.child(!lastDirection)) && !isRed(sibling.child(lastDirection)) {
 						// Color flip
@@ -172,9 +229,9 @@ func delete[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T]
 					} else {
 						direction2 := grandParent.right == parent
 						if isRed(sibling.child(lastDirection)) {
-							grandParent.setChild(direction2, doubleRotate(parent, lastDirection))
+							grandParent.setChild(direction2, doubleRotate(parent, lastDirection, cow, counter))
 						} else if isRed(sibling.child(!lastDirection)) {
-							grandParent.setChild(direction2, singleRotate(parent, lastDirection))
+							grandParent.setChild(direction2, singleRotate(parent, lastDirection, cow, counter))
 						}
 
 						// When performing a rotation to parent, child is not affected.
@@ -186,9 +243,10 @@ func delete[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T]
 
 						// Ensure correct coloring
 						child.color = red
-						grandParent.child(direction2).color = red
-						grandParent.child(direction2).left.color = black
-						grandParent.child(direction2).right.color = black
+						top := grandParent.child(direction2)
+						top.color = red
+						top.mutableChild(false, cow).color = black
+						top.mutableChild(true, cow).color = black
 					}
 				}
 			}
@@ -196,21 +254,23 @@ func delete[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T]
 	}
 
 	// Replace and remove the target node
-	if target != nil {
-		target.value = child.value
-		parent.setChild(parent.right == child, child.child(child.left == nil))
+	if target == nil {
+		target = child
 	}
+	removed := target.value
+	target.value = child.value
+	parent.setChild(parent.right == child, child.child(child.left == nil))
 
 	// Update root and make it black
 	root = superRoot.right
 	if root != nil {
 		root.color = black
 	}
-	return root
+	return root, removed
 }
 
 func (t *RBTree[T]) Delete(value T) {
-	t.root = delete(t.root, value)
+	t.root, _ = delete(t.root, value, t.cmp, t.cow, t.counter)
 }
 
 func (t *RBTree[T]) Size() uint {
@@ -223,7 +283,8 @@ func (t *RBTree[T]) Size() uint {
 func (t *RBTree[T]) At(k uint) (T, error) {
 	result := at(t.root, k)
 	if result == nil {
-		return T(rune(0)), bstrees.ErrIndexIsOutOfRange
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
 	}
 	return result.value, nil
 }
@@ -236,10 +297,19 @@ func (t *RBTree[T]) Clear() {
 	t.root = nil
 }
 
-func index[T constraints.Ordered](root *rbTreeNode[T], value T) uint {
+// Snapshot returns a tree holding the values of t in O(1). The two trees
+// share their nodes, and a node is only copied when either tree modifies it,
+// so the snapshot can be read while t is being modified. Snapshot itself
+// modifies t.
+func (t *RBTree[T]) Snapshot() *RBTree[T] {
+	t.cow = new(copyOnWrite)
+	return &RBTree[T]{root: t.root, cmp: t.cmp, options: t.options, cow: new(copyOnWrite), counter: t.counter}
+}
+
+func index[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int) uint {
 	rank := uint(0)
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			rank += 1
 			if root.left != nil {
 				rank += root.left.size
@@ -253,13 +323,13 @@ func index[T constraints.Ordered](root *rbTreeNode[T], value T) uint {
 }
 
 func (t *RBTree[T]) Index(value T) uint {
-	return index(t.root, value)
+	return index(t.root, value, t.cmp)
 }
 
-func predecessor[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T] {
+func predecessor[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int) *rbTreeNode[T] {
 	var prev *rbTreeNode[T] = nil
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			prev = root
 			root = root.right
 		} else {
@@ -270,17 +340,18 @@ func predecessor[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNod
 }
 
 func (t *RBTree[T]) Predecessor(value T) (T, error) {
-	prev := predecessor(t.root, value)
+	prev := predecessor(t.root, value, t.cmp)
 	if prev == nil {
-		return T(rune(0)), bstrees.ErrPredecessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
 	}
 	return prev.value, nil
 }
 
-func successor[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[T] {
+func successor[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int) *rbTreeNode[T] {
 	var next *rbTreeNode[T] = nil
 	for root != nil {
-		if root.value > value {
+		if cmp(root.value, value) > 0 {
 			next = root
 			root = root.left
 		} else {
@@ -291,9 +362,163 @@ func successor[T constraints.Ordered](root *rbTreeNode[T], value T) *rbTreeNode[
 }
 
 func (t *RBTree[T]) Successor(value T) (T, error) {
-	next := successor(t.root, value)
+	next := successor(t.root, value, t.cmp)
 	if next == nil {
-		return T(rune(0)), bstrees.ErrSuccessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
 	return next.value, nil
 }
+
+func floor[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int) *rbTreeNode[T] {
+	var result *rbTreeNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c < 0 {
+			result = root
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return result
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *RBTree[T]) Floor(value T) (T, bool) {
+	result := floor(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func ceiling[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int) *rbTreeNode[T] {
+	var result *rbTreeNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c > 0 {
+			result = root
+			root = root.left
+		} else {
+			root = root.right
+		}
+	}
+	return result
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *RBTree[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func minimum[T any](root *rbTreeNode[T]) *rbTreeNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.left != nil {
+		root = root.left
+	}
+	return root
+}
+
+func maximum[T any](root *rbTreeNode[T]) *rbTreeNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.right != nil {
+		root = root.right
+	}
+	return root
+}
+
+// Min returns the smallest value.
+func (t *RBTree[T]) Min() (T, bool) {
+	result := minimum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *RBTree[T]) Max() (T, bool) {
+	result := maximum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *RBTree[T]) PopMin() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed T
+	t.root, removed = remove(t.root, func(*rbTreeNode[T]) int { return +1 }, t.cow, t.counter)
+	return removed, true
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *RBTree[T]) PopMax() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed T
+	t.root, removed = remove(t.root, func(*rbTreeNode[T]) int { return -1 }, t.cow, t.counter)
+	return removed, true
+}
+
+// Rank of the least value greater than value
+func upperIndex[T any](root *rbTreeNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) <= 0 {
+			rank += 1
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Count returns the number of copies of value.
+func (t *RBTree[T]) Count(value T) uint {
+	first, last := t.EqualRange(value)
+	return last - first
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *RBTree[T]) EqualRange(value T) (first, last uint) {
+	return index(t.root, value, t.cmp), upperIndex(t.root, value, t.cmp)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *RBTree[T]) DeleteAll(value T) uint {
+	left, right := splitBy(t.root, func(v T) bool {
+		return t.cmp(v, value) <= 0
+	}, t.cow, t.counter)
+	left, mid := splitBy(left, func(v T) bool {
+		return t.cmp(v, value) < 0
+	}, t.cow, t.counter)
+	t.root = join2(left, right, t.cow, t.counter)
+	return size(mid)
+}
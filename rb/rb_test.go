@@ -0,0 +1,95 @@
+package rb_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+	"github.com/yanglinshu/bstrees/v2/rb"
+)
+
+func TestTree(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return rb.New[int]() })
+}
+
+func TestTreeFunc(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return rb.NewFunc(func(a, b int) int { return a - b })
+	})
+}
+
+func TestMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return rb.NewMap[int, int]() })
+}
+
+func TestSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return rb.New[int](rb.Unique()) })
+}
+
+func TestFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return rb.FromSorted(values) })
+}
+
+func TestFromSortedUnique(t *testing.T) {
+	tree := rb.FromSorted([]int{1, 1, 2, 3, 3, 3}, rb.Unique())
+	if size := tree.Size(); size != 3 {
+		t.Errorf("Size() = %d, want 3", size)
+	}
+	if tree.Insert(2) {
+		t.Error("Insert(2) = true, want false")
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *rb.RBTree[int] { return rb.New[int]() },
+		(*rb.RBTree[int]).SplitAt, (*rb.RBTree[int]).SplitRank, rb.Join[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *rb.RBTree[int] { return rb.New[int]() },
+		rb.Union[int], rb.Intersection[int], rb.Difference[int])
+}
+
+func TestSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *rb.RBTree[int] { return rb.New[int](rb.Unique()) },
+		rb.Union[int], rb.Intersection[int], rb.Difference[int])
+}
+
+func TestSnapshot(t *testing.T) {
+	bstreestest.RunSnapshotSuite(t, func() *rb.RBTree[int] { return rb.New[int]() },
+		(*rb.RBTree[int]).Snapshot, (*rb.RBTree[int]).SplitAt, rb.Join[int])
+}
+
+func TestEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *rb.RBTree[int] { return rb.New[int]() },
+		func() *rb.RBTree[int] { return rb.New[int](rb.PreserveShape()) })
+}
+
+func TestString(t *testing.T) {
+	tree := rb.FromSorted([]int{1, 2, 3})
+	want := `2 (black)
+├── 1 (black)
+└── 3 (black)`
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := rb.New[int]()
+	data := `{"nodes":[{"value":2,"left":true,"right":true},{"value":1,"red":true,"left":true},{"value":0,"red":true},{"value":3}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 1: red node has a red child") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 1: red node has a red child")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func BenchmarkRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return rb.New[int]() })
+}
@@ -0,0 +1,32 @@
+package rb
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yanglinshu/bstrees/v2/internal/render"
+)
+
+func describe[T any](root *rbTreeNode[T]) *render.Node {
+	if root == nil {
+		return nil
+	}
+	n := &render.Node{Value: fmt.Sprint(root.value), Meta: "black", Color: "black"}
+	if root.color == red {
+		n.Meta, n.Color = "red", "red"
+	}
+	n.Left = describe(root.left)
+	n.Right = describe(root.right)
+	return n
+}
+
+// WriteDOT writes the structure of t as a Graphviz digraph, each node being
+// labeled with its color.
+func (t *RBTree[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, describe(t.root))
+}
+
+// String draws the structure of t as text, see WriteDOT.
+func (t *RBTree[T]) String() string {
+	return render.String(describe(t.root))
+}
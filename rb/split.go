@@ -0,0 +1,185 @@
+package rb
+
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
+
+func size[T any](root *rbTreeNode[T]) uint {
+	if root == nil {
+		return 0
+	}
+	return root.size
+}
+
+// Count the black nodes on the leftmost path of root
+func blackHeight[T any](root *rbTreeNode[T]) int {
+	height := 0
+	for ; root != nil; root = root.left {
+		if !root.red() {
+			height++
+		}
+	}
+	return height
+}
+
+// Rotate root towards !direction, keeping the colors of the nodes
+func rotate[T any](root *rbTreeNode[T], direction bool, cow *copyOnWrite, counter *stats.Counter) *rbTreeNode[T] {
+	counter.Rotation()
+	root = root.mutable(cow)
+	save := root.mutableChild(!direction, cow)
+	root.setChild(!direction, save.child(direction))
+	save.setChild(direction, root)
+	root.Update()
+	save.Update()
+	return save
+}
+
+// Attach middle and right to the right spine of left at black height
+// rightHeight, where left is higher than right. The result may have a red
+// root with a red right child.
+func joinRight[T any](left, middle, right *rbTreeNode[T], leftHeight, rightHeight int, cow *copyOnWrite, counter *stats.Counter) *rbTreeNode[T] {
+	if !isRed(left) && leftHeight == rightHeight {
+		middle = middle.mutable(cow)
+		middle.left = left
+		middle.right = right
+		middle.color = red
+		middle.Update()
+		return middle
+	}
+	childHeight := leftHeight
+	if !left.red() {
+		childHeight--
+	}
+	left = left.mutable(cow)
+	left.right = joinRight(left.right, middle, right, childHeight, rightHeight, cow, counter)
+	left.Update()
+	if !left.red() && isRed(left.right) && isRed(left.right.right) {
+		left.right.mutableChild(true, cow).color = black
+		return rotate(left, false, cow, counter)
+	}
+	return left
+}
+
+// Mirror of joinRight, where right is higher than left
+func joinLeft[T any](left, middle, right *rbTreeNode[T], leftHeight, rightHeight int, cow *copyOnWrite, counter *stats.Counter) *rbTreeNode[T] {
+	if !isRed(right) && leftHeight == rightHeight {
+		middle = middle.mutable(cow)
+		middle.left = left
+		middle.right = right
+		middle.color = red
+		middle.Update()
+		return middle
+	}
+	childHeight := rightHeight
+	if !right.red() {
+		childHeight--
+	}
+	right = right.mutable(cow)
+	right.left = joinLeft(left, middle, right.left, leftHeight, childHeight, cow, counter)
+	right.Update()
+	if !right.red() && isRed(right.left) && isRed(right.left.left) {
+		right.left.mutableChild(false, cow).color = black
+		return rotate(right, true, cow, counter)
+	}
+	return right
+}
+
+// Join left, the single node middle and right, where left <= middle <= right
+func join3[T any](left, middle, right *rbTreeNode[T], cow *copyOnWrite, counter *stats.Counter) *rbTreeNode[T] {
+	if isRed(left) {
+		left = left.mutable(cow)
+		left.color = black
+	}
+	if isRed(right) {
+		right = right.mutable(cow)
+		right.color = black
+	}
+	var root *rbTreeNode[T]
+	leftHeight, rightHeight := blackHeight(left), blackHeight(right)
+	if leftHeight > rightHeight {
+		root = joinRight(left, middle, right, leftHeight, rightHeight, cow, counter)
+	} else if rightHeight > leftHeight {
+		root = joinLeft(left, middle, right, leftHeight, rightHeight, cow, counter)
+	} else {
+		middle = middle.mutable(cow)
+		middle.left = left
+		middle.right = right
+		middle.Update()
+		root = middle
+	}
+	root.color = black
+	return root
+}
+
+// Join left and right, where left <= right
+func join2[T any](left, right *rbTreeNode[T], cow *copyOnWrite, counter *stats.Counter) *rbTreeNode[T] {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	right, value := remove(right, func(node *rbTreeNode[T]) int {
+		return 1
+	}, cow, counter)
+	return join3(left, newRBTreeNode(value, cow), right, cow, counter)
+}
+
+// Split root into the values for which left returns true and the others,
+// where left returns true for a prefix of the values
+func splitBy[T any](root *rbTreeNode[T], left func(value T) bool, cow *copyOnWrite, counter *stats.Counter) (*rbTreeNode[T], *rbTreeNode[T]) {
+	if root == nil {
+		return nil, nil
+	}
+	rootLeft, rootRight := root.left, root.right
+	if left(root.value) {
+		rightLeft, rightRight := splitBy(rootRight, left, cow, counter)
+		return join3(rootLeft, root, rightLeft, cow, counter), rightRight
+	} else {
+		leftLeft, leftRight := splitBy(rootLeft, left, cow, counter)
+		return leftLeft, join3(leftRight, root, rootRight, cow, counter)
+	}
+}
+
+// Split root into the k smallest values and the others
+func splitRank[T any](root *rbTreeNode[T], k uint, cow *copyOnWrite, counter *stats.Counter) (*rbTreeNode[T], *rbTreeNode[T]) {
+	if root == nil {
+		return nil, nil
+	}
+	left, right := root.left, root.right
+	if leftSize := size(left); k <= leftSize {
+		leftLeft, leftRight := splitRank(left, k, cow, counter)
+		return leftLeft, join3(leftRight, root, right, cow, counter)
+	} else {
+		rightLeft, rightRight := splitRank(right, k-leftSize-1, cow, counter)
+		return join3(left, root, rightLeft, cow, counter), rightRight
+	}
+}
+
+// SplitAt moves the values less than value to left and the others to right
+// in O(log n). t is left empty.
+func (t *RBTree[T]) SplitAt(value T) (left, right *RBTree[T]) {
+	l, r := splitBy(t.root, func(v T) bool {
+		return t.cmp(v, value) < 0
+	}, t.cow, t.counter)
+	t.root = nil
+	return &RBTree[T]{root: l, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}, &RBTree[T]{root: r, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// O(log n). t is left empty.
+func (t *RBTree[T]) SplitRank(k uint) (left, right *RBTree[T]) {
+	l, r := splitRank(t.root, k, t.cow, t.counter)
+	t.root = nil
+	return &RBTree[T]{root: l, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}, &RBTree[T]{root: r, cmp: t.cmp, options: t.options, cow: t.cow.fork(), counter: t.counter}
+}
+
+// Join moves the values of left and right to a new tree in O(log n). Every
+// value of left must be less than every value of right, or equal to it if
+// the trees are not sets, which is not checked. The new tree is ordered like
+// left, and left and right are left empty.
+func Join[T any](left, right *RBTree[T]) *RBTree[T] {
+	cow := joinCOW(left.cow, right.cow)
+	result := &RBTree[T]{root: join2(left.root, right.root, cow, left.counter), cmp: left.cmp, options: left.options, cow: cow, counter: left.counter}
+	left.root = nil
+	right.root = nil
+	return result
+}
@@ -0,0 +1,47 @@
+package rb
+
+import "github.com/yanglinshu/bstrees/v2/internal/invariant"
+
+// Check root and returns the number of black nodes on any path from it down
+// to a leaf
+func validate[T any](root *rbTreeNode[T], order *invariant.Order[T]) (int, error) {
+	if root == nil {
+		return 0, nil
+	}
+	left, err := validate(root.left, order)
+	if err != nil {
+		return 0, err
+	}
+	if err := order.Next(root.value); err != nil {
+		return 0, err
+	}
+	right, err := validate(root.right, order)
+	if err != nil {
+		return 0, err
+	}
+	if want := size(root.left) + size(root.right) + 1; root.size != want {
+		return 0, invariant.Errorf(root.value, "size is %d, want %d", root.size, want)
+	}
+	if root.red() && (isRed(root.left) || isRed(root.right)) {
+		return 0, invariant.Errorf(root.value, "red node has a red child")
+	}
+	if left != right {
+		return 0, invariant.Errorf(root.value, "black height is %d on the left and %d on the right", left, right)
+	}
+	if !root.red() {
+		left++
+	}
+	return left, nil
+}
+
+// Validate checks the order of the values, the cached size of every node and
+// the red-black rules: the root is black, a red node has no red child and
+// every path down to a leaf has as many black nodes. Returns a
+// *bstrees.InvariantError naming the first node found to break a rule.
+func (t *RBTree[T]) Validate() error {
+	if isRed(t.root) {
+		return invariant.Errorf(t.root.value, "root is red")
+	}
+	_, err := validate(t.root, invariant.NewOrder(t.cmp, t.unique))
+	return err
+}
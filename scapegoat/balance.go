@@ -1,25 +1,21 @@
 package scapegoat
 
-import "golang.org/x/exp/constraints"
+import "math"
 
-func toSlice[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T]) []*scapeGoatTreeNode[T] {
-	if root == nil {
-		return []*scapeGoatTreeNode[T]{}
-	}
-	if root.active() {
-		defer func() {
-			root.left = nil
-			root.right = nil
-			root.size = 1
-			root.weight = 1
-		}()
-		return append(append(toSlice(root.left), root), toSlice(root.right)...)
-	} else {
-		return append(toSlice(root.left), toSlice(root.right)...)
+// Append the nodes of root to nodes in order, the deleted ones only if all is
+// set. nodes is not reallocated if it has room for them.
+func flatten[T any](root *scapeGoatTreeNode[T], nodes []*scapeGoatTreeNode[T], all bool) []*scapeGoatTreeNode[T] {
+	for root != nil {
+		nodes = flatten(root.left, nodes, all)
+		if all || root.active() {
+			nodes = append(nodes, root)
+		}
+		root = root.right
 	}
+	return nodes
 }
 
-func fromSlice[T constraints.Integer | constraints.Float](slice []*scapeGoatTreeNode[T]) *scapeGoatTreeNode[T] {
+func fromSlice[T any](slice []*scapeGoatTreeNode[T]) *scapeGoatTreeNode[T] {
 	if len(slice) == 0 {
 		return nil
 	}
@@ -31,19 +27,29 @@ func fromSlice[T constraints.Integer | constraints.Float](slice []*scapeGoatTree
 	return root
 }
 
-func reconstruct[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T]) *scapeGoatTreeNode[T] {
-	return fromSlice(toSlice(root))
+// Rebuild root into a perfectly balanced tree made of the same nodes, without
+// the deleted ones unless all is set. The nodes are gathered in t.buffer, which
+// is kept for the next rebuild so that rebuilding does not allocate.
+func (t *ScapeGoatTree[T]) rebuild(root *scapeGoatTreeNode[T], all bool) *scapeGoatTreeNode[T] {
+	t.buffer = flatten(root, t.buffer[:0], all)
+	t.counter.Rebuild(len(t.buffer))
+	root = fromSlice(t.buffer)
+	for i := range t.buffer {
+		t.buffer[i] = nil
+	}
+	return root
 }
 
-func imbalance[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], alpha float64) bool {
-	if root == nil {
-		return false
-	}
-	if root.left != nil && root.left.weight > uint(alpha*float64(root.weight)) {
-		return true
-	}
-	if root.right != nil && root.right.weight > uint(alpha*float64(root.weight)) {
-		return true
+// Greatest depth allowed in a tree of n nodes, log_{1/alpha}(n) rounded down
+func maxDepth(n uint, alpha float64) int {
+	return int(math.Log(float64(n)) / math.Log(1/alpha))
+}
+
+// Build a balanced tree from sorted values
+func fromSorted[T any](values []T) *scapeGoatTreeNode[T] {
+	nodes := make([]*scapeGoatTreeNode[T], len(values))
+	for i, value := range values {
+		nodes[i] = newScapeGoatTreeNode(value)
 	}
-	return false
+	return fromSlice(nodes)
 }
@@ -1,7 +1,5 @@
 package scapegoat
 
-import "golang.org/x/exp/constraints"
-
 type nodeState bool
 
 const (
@@ -9,7 +7,7 @@ const (
 	active   nodeState = true
 )
 
-type scapeGoatTreeNode[T constraints.Integer | constraints.Float] struct {
+type scapeGoatTreeNode[T any] struct {
 	value  T
 	left   *scapeGoatTreeNode[T]
 	right  *scapeGoatTreeNode[T]
@@ -18,7 +16,7 @@ type scapeGoatTreeNode[T constraints.Integer | constraints.Float] struct {
 	weight uint // Number of nodes in the subtree
 }
 
-func newScapeGoatTreeNode[T constraints.Integer | constraints.Float](value T) *scapeGoatTreeNode[T] {
+func newScapeGoatTreeNode[T any](value T) *scapeGoatTreeNode[T] {
 	return &scapeGoatTreeNode[T]{
 		value:  value,
 		left:   nil,
@@ -49,8 +47,3 @@ func (n *scapeGoatTreeNode[T]) update() {
 		n.weight += n.right.weight
 	}
 }
-
-func (n *scapeGoatTreeNode[T]) deactivate() {
-	n.state = inactive
-	n.update()
-}
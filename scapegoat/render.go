@@ -0,0 +1,32 @@
+package scapegoat
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yanglinshu/bstrees/v2/internal/render"
+)
+
+func describe[T any](root *scapeGoatTreeNode[T]) *render.Node {
+	if root == nil {
+		return nil
+	}
+	n := &render.Node{Value: fmt.Sprint(root.value), Meta: fmt.Sprintf("state=active weight=%d", root.weight)}
+	if !root.active() {
+		n.Meta, n.Dashed = fmt.Sprintf("state=inactive weight=%d", root.weight), true
+	}
+	n.Left = describe(root.left)
+	n.Right = describe(root.right)
+	return n
+}
+
+// WriteDOT writes the structure of t as a Graphviz digraph, each node being
+// labeled with its state and the number of nodes in its subtree, deleted nodes being dashed.
+func (t *ScapeGoatTree[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, describe(t.root))
+}
+
+// String draws the structure of t as text, see WriteDOT.
+func (t *ScapeGoatTree[T]) String() string {
+	return render.String(describe(t.root))
+}
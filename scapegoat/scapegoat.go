@@ -1,46 +1,165 @@
 package scapegoat
 
 import (
+	"fmt"
+
 	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
 	"golang.org/x/exp/constraints"
 )
 
-type ScapeGoatTree[T constraints.Integer | constraints.Float] struct {
-	root  *scapeGoatTreeNode[T]
-	alpha float64
+var _ bstrees.Tree[int] = (*ScapeGoatTree[int])(nil)
+
+// ScapeGoatTree must be created by a constructor such as New or NewFunc.
+type ScapeGoatTree[T any] struct {
+	root    *scapeGoatTreeNode[T]
+	alpha   float64
+	maxSize uint // Greatest size since the last global rebuild
+	cmp     func(a, b T) int
+	path    []*scapeGoatTreeNode[T] // Scratch space of Insert
+	buffer  []*scapeGoatTreeNode[T] // Scratch space of rebuild
+	counter *stats.Counter          // Shared with the trees made from this one
+	options
 }
 
-func New[T constraints.Integer | constraints.Float](alpha float64) *ScapeGoatTree[T] {
+func New[T constraints.Ordered](alpha float64, opts ...Option) *ScapeGoatTree[T] {
+	return NewFunc(alpha, bstrees.Compare[T], opts...)
+}
+
+// NewFunc creates a tree ordered by cmp, which returns a negative number when
+// a < b, a positive number when a > b and zero when a == b. The depth of the
+// tree is kept under log_{1/alpha}(n), so alpha trades lookups for rebuilds.
+// It panics if alpha is not strictly between 0.5 and 1.
+func NewFunc[T any](alpha float64, cmp func(a, b T) int, opts ...Option) *ScapeGoatTree[T] {
+	if !(alpha > 0.5 && alpha < 1) {
+		panic(fmt.Sprintf("scapegoat: alpha is %v, want a value in (0.5, 1)", alpha))
+	}
+	counter := new(stats.Counter)
 	return &ScapeGoatTree[T]{
-		root:  nil,
-		alpha: alpha,
+		root:    nil,
+		alpha:   alpha,
+		cmp:     stats.Compare(counter, cmp),
+		counter: counter,
+		options: newOptions(opts),
 	}
 }
 
-func insert[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], value T, alpha float64) *scapeGoatTreeNode[T] {
-	if root == nil {
-		return newScapeGoatTreeNode(value)
+// FromSorted builds a tree from values sorted in ascending order in O(n).
+// The order of values is not checked.
+func FromSorted[T constraints.Ordered](alpha float64, values []T, opts ...Option) *ScapeGoatTree[T] {
+	return FromSortedFunc(alpha, bstrees.Compare[T], values, opts...)
+}
+
+// FromSortedFunc builds a tree ordered by cmp from values sorted by cmp in
+// O(n), see NewFunc and FromSorted.
+func FromSortedFunc[T any](alpha float64, cmp func(a, b T) int, values []T, opts ...Option) *ScapeGoatTree[T] {
+	t := NewFunc(alpha, cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
 	}
-	if value < root.value {
-		root.left = insert(root.left, value, alpha)
-	} else {
-		root.right = insert(root.right, value, alpha)
+	t.root = fromSorted(values)
+	return t
+}
+
+// Insert value, unless the tree is a set and value is already present. In a
+// set, a deleted node holding value is reactivated instead. When the new node
+// is deeper than log_{1/alpha}(n), its first ancestor whose height exceeds
+// log_{1/alpha} of its weight is the scapegoat and is rebuilt.
+func (t *ScapeGoatTree[T]) Insert(value T) bool {
+	_, inserted := t.insert(value)
+	return inserted
+}
+
+// Same as Insert, but also returns the node holding value
+func (t *ScapeGoatTree[T]) insert(value T) (*scapeGoatTreeNode[T], bool) {
+	path, link := locate(&t.root, value, t.cmp, t.unique, t.path[:0])
+	if root := *link; root != nil {
+		if root.active() {
+			t.release(path)
+			return root, false
+		}
+		root.value = value
+		root.state = active
+		root.size++
+		for _, node := range path {
+			node.size++
+		}
+		t.release(path)
+		t.grow()
+		return root, true
 	}
-	root.update()
-	if imbalance(root, alpha) {
-		return reconstruct(root)
+	leaf := newScapeGoatTreeNode(value)
+	*link = leaf
+	for _, node := range path {
+		node.size++
+		node.weight++
 	}
-	return root
+	t.grow()
+	if depth := len(path); depth > maxDepth(t.root.weight, t.alpha) {
+		for i := depth - 1; i >= 0; i-- {
+			if depth-i > maxDepth(path[i].weight, t.alpha) {
+				// Deleted nodes are kept, so that the weights of the
+				// ancestors, which bound their depth, are left as they are
+				t.replace(path[:i], path[i], t.rebuild(path[i], true))
+				break
+			}
+		}
+	}
+	t.release(path)
+	return leaf, true
+}
+
+// Follow the links from link down to the empty one where value belongs,
+// appending the nodes passed to path. In a set, stop at the link to the node
+// holding value instead if there is one.
+func locate[T any](link **scapeGoatTreeNode[T], value T, cmp func(a, b T) int, unique bool, path []*scapeGoatTreeNode[T]) ([]*scapeGoatTreeNode[T], **scapeGoatTreeNode[T]) {
+	for *link != nil {
+		root := *link
+		c := cmp(value, root.value)
+		if c == 0 && unique {
+			break
+		}
+		path = append(path, root)
+		if c < 0 {
+			link = &root.left
+		} else {
+			link = &root.right
+		}
+	}
+	return path, link
 }
 
-func (t *ScapeGoatTree[T]) Insert(value T) {
-	t.root = insert(t.root, value, t.alpha)
+// Keep the memory of path for the next insertion, without its nodes
+func (t *ScapeGoatTree[T]) release(path []*scapeGoatTreeNode[T]) {
+	for i := range path {
+		path[i] = nil
+	}
+	t.path = path[:0]
+}
+
+// Record a new greatest size
+func (t *ScapeGoatTree[T]) grow() {
+	if size := t.Size(); size > t.maxSize {
+		t.maxSize = size
+	}
+}
+
+// Put node in place of old, whose ancestors are path
+func (t *ScapeGoatTree[T]) replace(path []*scapeGoatTreeNode[T], old, node *scapeGoatTreeNode[T]) {
+	if len(path) == 0 {
+		t.root = node
+	} else if parent := path[len(path)-1]; parent.left == old {
+		parent.left = node
+	} else {
+		parent.right = node
+	}
 }
 
-func index[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], value T) uint {
+func index[T any](root *scapeGoatTreeNode[T], value T, cmp func(a, b T) int) uint {
 	result := uint(0)
 	for root != nil {
-		if root.value >= value {
+		if cmp(root.value, value) >= 0 {
 			root = root.left
 		} else {
 			if root.left != nil {
@@ -56,10 +175,29 @@ func index[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T]
 }
 
 func (t *ScapeGoatTree[T]) Index(value T) uint {
-	return index(t.root, value)
+	return index(t.root, value, t.cmp)
 }
 
-func at[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], k uint) *scapeGoatTreeNode[T] {
+// Rank of the least value greater than value
+func upperIndex[T any](root *scapeGoatTreeNode[T], value T, cmp func(a, b T) int) uint {
+	result := uint(0)
+	for root != nil {
+		if cmp(root.value, value) > 0 {
+			root = root.left
+		} else {
+			if root.left != nil {
+				result += root.left.size
+			}
+			if root.active() {
+				result += 1
+			}
+			root = root.right
+		}
+	}
+	return result + 1
+}
+
+func at[T any](root *scapeGoatTreeNode[T], k uint) *scapeGoatTreeNode[T] {
 	var result *scapeGoatTreeNode[T] = nil
 	for root != nil {
 		leftSize := uint(0)
@@ -85,108 +223,239 @@ func at[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], k
 func (t *ScapeGoatTree[T]) At(k uint) (T, error) {
 	result := at(t.root, k)
 	if result == nil {
-		return T(rune(0)), bstrees.ErrIndexIsOutOfRange
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
 	}
 	return result.value, nil
 }
 
-func search[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], value T) *scapeGoatTreeNode[T] {
-	if root == nil {
-		return nil
-	}
-	if root.value == value {
-		if root.active() {
-			return root
-		} else {
-			if result := search(root.left, value); result != nil {
-				return result
-			} else if result := search(root.right, value); result != nil {
-				return result
-			}
-			return nil
-		}
-	} else if root.value > value {
-		return search(root.left, value)
-	} else {
-		return search(root.right, value)
+// Find the active node holding value by its rank, since the copies of value
+// may be on both sides of a deleted one
+func search[T any](root *scapeGoatTreeNode[T], value T, cmp func(a, b T) int) *scapeGoatTreeNode[T] {
+	if p := at(root, index(root, value, cmp)); p != nil && cmp(p.value, value) == 0 {
+		return p
 	}
+	return nil
 }
 
 func (t *ScapeGoatTree[T]) Contains(value T) bool {
-	return search(t.root, value) != nil
+	return search(t.root, value, t.cmp) != nil
 }
 
-func delete[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], value T) *scapeGoatTreeNode[T] {
-	if root == nil {
-		return nil
-	}
-	if root.value == value {
-		if root.active() {
-			root.deactivate()
-			return root
-		} else {
-			if result := delete(root.left, value); result != nil {
-				root.size -= 1
-				return result
-			} else if result := delete(root.right, value); result != nil {
-				root.size -= 1
-				return result
-			}
-			return nil
-		}
-	} else if root.value > value {
-		if result := delete(root.left, value); result != nil {
-			root.size -= 1
-			return result
-		}
-	} else {
-		if result := delete(root.right, value); result != nil {
-			root.size -= 1
-			return result
+func (t *ScapeGoatTree[T]) Delete(value T) {
+	t.delete(value)
+}
+
+// Same as Delete, but also returns whether value has been deleted. In a set,
+// the node holding value is found in a single descent.
+func (t *ScapeGoatTree[T]) delete(value T) bool {
+	if !t.unique {
+		k := index(t.root, value, t.cmp)
+		if p := at(t.root, k); p == nil || t.cmp(p.value, value) != 0 {
+			return false
 		}
+		t.deleteAt(k)
+		return true
 	}
-	return nil
+	path, link := locate(&t.root, value, t.cmp, true, t.path[:0])
+	root := *link
+	if root == nil || !root.active() {
+		t.release(path)
+		return false
+	}
+	t.grow()
+	root.state = inactive
+	root.size--
+	for _, node := range path {
+		node.size--
+	}
+	t.release(path)
+	t.shrink()
+	return true
 }
 
-func (t *ScapeGoatTree[T]) Delete(value T) {
-	target := search(t.root, value)
-	if target != nil {
-		delete(t.root, value)
+// Remove the k-th value
+func (t *ScapeGoatTree[T]) deleteAt(k uint) T {
+	t.grow()
+	value := deleteAt(t.root, k).value
+	t.shrink()
+	return value
+}
+
+// Rebuild the whole tree without its deleted nodes once the values are fewer
+// than alpha times the most there have been since the last global rebuild
+func (t *ScapeGoatTree[T]) shrink() {
+	if float64(t.Size()) < t.alpha*float64(t.maxSize) {
+		t.Compact()
 	}
 }
 
+// Compact rebuilds the tree into a balanced one in O(n), removing the nodes
+// of the deleted values.
+func (t *ScapeGoatTree[T]) Compact() {
+	t.root = t.rebuild(t.root, false)
+	t.maxSize = t.Size()
+}
+
+// TombstoneRatio returns the share of the nodes of the tree which hold a
+// deleted value, and are only removed by a rebuild.
+func (t *ScapeGoatTree[T]) TombstoneRatio() float64 {
+	if t.root == nil {
+		return 0
+	}
+	return float64(t.root.weight-t.root.size) / float64(t.root.weight)
+}
+
 func (t *ScapeGoatTree[T]) Clear() {
 	t.root = nil
+	t.maxSize = 0
+	t.path = nil
+	t.buffer = nil
 }
 
 func (t *ScapeGoatTree[T]) Size() uint {
+	if t.root == nil {
+		return 0
+	}
 	return t.root.size
 }
 
 func (t *ScapeGoatTree[T]) Empty() bool {
-	return t.root == nil
+	return t.Size() == 0 // Deleted nodes are only deactivated, so root may be non-nil
 }
 
-func predecessor[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], value T) *scapeGoatTreeNode[T] {
-	return at(root, index(root, value)-1)
+func predecessor[T any](root *scapeGoatTreeNode[T], value T, cmp func(a, b T) int) *scapeGoatTreeNode[T] {
+	return at(root, index(root, value, cmp)-1)
 }
 
 func (t *ScapeGoatTree[T]) Predecessor(value T) (T, error) {
-	prev := predecessor(t.root, value)
+	prev := predecessor(t.root, value, t.cmp)
 	if prev == nil {
-		return T(rune(0)), bstrees.ErrPredecessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
 	}
 	return prev.value, nil
 }
 
-func successor[T constraints.Integer | constraints.Float](root *scapeGoatTreeNode[T], value T) *scapeGoatTreeNode[T] {
-	return at(root, index(root, value+1))
+func successor[T any](root *scapeGoatTreeNode[T], value T, cmp func(a, b T) int) *scapeGoatTreeNode[T] {
+	return at(root, upperIndex(root, value, cmp))
 }
 
 func (t *ScapeGoatTree[T]) Successor(value T) (T, error) {
-	next := successor(t.root, value)
+	next := successor(t.root, value, t.cmp)
 	if next == nil {
-		return T(rune(0)), bstrees.ErrSuccessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
 	return next.value, nil
 }
+
+func floor[T any](root *scapeGoatTreeNode[T], value T, cmp func(a, b T) int) *scapeGoatTreeNode[T] {
+	return at(root, upperIndex(root, value, cmp)-1)
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *ScapeGoatTree[T]) Floor(value T) (T, bool) {
+	result := floor(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func ceiling[T any](root *scapeGoatTreeNode[T], value T, cmp func(a, b T) int) *scapeGoatTreeNode[T] {
+	return at(root, index(root, value, cmp))
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *ScapeGoatTree[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Deactivate the k-th active node, k must be in [1, size]
+func deleteAt[T any](root *scapeGoatTreeNode[T], k uint) *scapeGoatTreeNode[T] {
+	for {
+		root.size -= 1
+		leftSize := uint(0)
+		if root.left != nil {
+			leftSize = root.left.size
+		}
+		if root.active() && leftSize+1 == k {
+			root.state = inactive
+			return root
+		} else if leftSize >= k {
+			root = root.left
+		} else {
+			k -= leftSize
+			if root.active() {
+				k -= 1
+			}
+			root = root.right
+		}
+	}
+}
+
+// Min returns the smallest value.
+func (t *ScapeGoatTree[T]) Min() (T, bool) {
+	result := at(t.root, 1)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *ScapeGoatTree[T]) Max() (T, bool) {
+	result := at(t.root, t.Size())
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *ScapeGoatTree[T]) PopMin() (T, bool) {
+	if t.Size() == 0 {
+		var zero T
+		return zero, false
+	}
+	return t.deleteAt(1), true
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *ScapeGoatTree[T]) PopMax() (T, bool) {
+	if t.Size() == 0 {
+		var zero T
+		return zero, false
+	}
+	return t.deleteAt(t.Size()), true
+}
+
+// Count returns the number of copies of value.
+func (t *ScapeGoatTree[T]) Count(value T) uint {
+	first, last := t.EqualRange(value)
+	return last - first
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *ScapeGoatTree[T]) EqualRange(value T) (first, last uint) {
+	return index(t.root, value, t.cmp), upperIndex(t.root, value, t.cmp)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *ScapeGoatTree[T]) DeleteAll(value T) uint {
+	first, last := t.EqualRange(value)
+	for k := first; k < last; k++ {
+		t.deleteAt(first)
+	}
+	return last - first
+}
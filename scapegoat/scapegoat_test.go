@@ -0,0 +1,209 @@
+package scapegoat_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+	"github.com/yanglinshu/bstrees/v2/scapegoat"
+)
+
+func TestTree(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return scapegoat.New[int](0.7) })
+}
+
+func TestTreeFunc(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return scapegoat.NewFunc(0.7, func(a, b int) int { return a - b })
+	})
+}
+
+func TestMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return scapegoat.NewMap[int, int](0.7) })
+}
+
+func TestFloat(t *testing.T) {
+	tree := scapegoat.New[float64](0.7)
+	for _, value := range []float64{1.2, 1.5, 1.7, 2.1} {
+		tree.Insert(value)
+	}
+	if got, err := tree.Predecessor(1.5); err != nil || got != 1.2 {
+		t.Errorf("Predecessor(1.5) = %v, %v, want 1.2, nil", got, err)
+	}
+	if got, err := tree.Successor(1.5); err != nil || got != 1.7 {
+		t.Errorf("Successor(1.5) = %v, %v, want 1.7, nil", got, err)
+	}
+	if got, ok := tree.Floor(1.6); !ok || got != 1.5 {
+		t.Errorf("Floor(1.6) = %v, %v, want 1.5, true", got, ok)
+	}
+	if got, ok := tree.Ceiling(1.6); !ok || got != 1.7 {
+		t.Errorf("Ceiling(1.6) = %v, %v, want 1.7, true", got, ok)
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	bstreestest.RunOrderedSuite(t, func() bstrees.Tree[string] { return scapegoat.New[string](0.7) },
+		func(values []uint) bstrees.Tree[uint] { return scapegoat.FromSorted(0.7, values) })
+}
+
+func TestSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return scapegoat.New[int](0.7, scapegoat.Unique()) })
+}
+
+func TestFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return scapegoat.FromSorted(0.7, values) })
+}
+
+func TestFromSortedUnique(t *testing.T) {
+	tree := scapegoat.FromSorted(0.7, []int{1, 1, 2, 3, 3, 3}, scapegoat.Unique())
+	if size := tree.Size(); size != 3 {
+		t.Errorf("Size() = %d, want 3", size)
+	}
+	if tree.Insert(2) {
+		t.Error("Insert(2) = true, want false")
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *scapegoat.ScapeGoatTree[int] { return scapegoat.New[int](0.7) },
+		(*scapegoat.ScapeGoatTree[int]).SplitAt, (*scapegoat.ScapeGoatTree[int]).SplitRank, scapegoat.Join[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *scapegoat.ScapeGoatTree[int] { return scapegoat.New[int](0.7) },
+		scapegoat.Union[int], scapegoat.Intersection[int], scapegoat.Difference[int])
+}
+
+func TestSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *scapegoat.ScapeGoatTree[int] { return scapegoat.New[int](0.7, scapegoat.Unique()) },
+		scapegoat.Union[int], scapegoat.Intersection[int], scapegoat.Difference[int])
+}
+
+func TestEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *scapegoat.ScapeGoatTree[int] { return scapegoat.New[int](0.7) },
+		func() *scapegoat.ScapeGoatTree[int] { return scapegoat.New[int](0.7, scapegoat.PreserveShape()) })
+}
+
+func TestString(t *testing.T) {
+	tree := scapegoat.FromSorted(0.7, []int{1, 2, 3, 4, 5})
+	tree.Delete(3)
+	want := `3 (state=inactive weight=5)
+├── 2 (state=active weight=2)
+│   ├── 1 (state=active weight=1)
+│   └── <nil>
+└── 5 (state=active weight=2)
+    ├── 4 (state=active weight=1)
+    └── <nil>`
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := scapegoat.New[int](0.6)
+	data := `{"nodes":[{"value":1,"right":true},{"value":2,"right":true},{"value":3,"right":true},{"value":4}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 4: is at depth 3, deeper than 2 in 4 nodes") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 4: is at depth 3, deeper than 2 in 4 nodes")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tree := scapegoat.New[int](0.7)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i)
+	}
+	for i := 0; i < 1000; i += 2 {
+		tree.Delete(i)
+		if ratio := tree.TombstoneRatio(); ratio > 0.3 {
+			t.Fatalf("TombstoneRatio() = %v after %d deletions, want at most 0.3", ratio, i/2+1)
+		}
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	tree.Delete(1)
+	ratio := tree.TombstoneRatio()
+	if ratio == 0 {
+		t.Fatal("TombstoneRatio() = 0, want a deleted node left")
+	}
+	if stats := tree.Stats(); stats.TombstoneRatio != ratio {
+		t.Errorf("Stats().TombstoneRatio = %v, want %v", stats.TombstoneRatio, ratio)
+	}
+	tree.Compact()
+	if ratio := tree.TombstoneRatio(); ratio != 0 {
+		t.Errorf("TombstoneRatio() = %v after Compact, want 0", ratio)
+	}
+	if size := tree.Size(); size != 499 {
+		t.Errorf("Size() = %d, want 499", size)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestDuplicatesAroundDeleted(t *testing.T) {
+	tree := scapegoat.FromSorted(0.7, []int{1, 2, 2, 2, 2, 2, 3})
+	tree.Delete(2) // The middle copy of 2 is the root
+	for i := 4; i > 0; i-- {
+		if !tree.Contains(2) {
+			t.Fatalf("Contains(2) = false with %d copies left", i)
+		}
+		if got := tree.Count(2); got != uint(i) {
+			t.Fatalf("Count(2) = %d, want %d", got, i)
+		}
+		tree.Delete(2)
+	}
+	if tree.Contains(2) {
+		t.Error("Contains(2) = true, want false")
+	}
+}
+
+func TestAlpha(t *testing.T) {
+	for _, alpha := range []float64{0, 0.5, 1, 1.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("New(%v) does not panic", alpha)
+				}
+			}()
+			scapegoat.New[int](alpha)
+		}()
+	}
+	for _, alpha := range []float64{0.51, 0.99} {
+		tree := scapegoat.New[int](alpha)
+		for i := 0; i < 1000; i++ {
+			tree.Insert(i)
+		}
+		if err := tree.Validate(); err != nil {
+			t.Errorf("New(%v): Validate() = %v", alpha, err)
+		}
+	}
+}
+
+func TestInsertAllocs(t *testing.T) {
+	tree := scapegoat.New[int](0.6)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i)
+	}
+	tree.Compact()
+	i := 1000
+	allocs := testing.AllocsPerRun(1000, func() {
+		tree.Insert(i)
+		i++
+	})
+	if allocs > 1 {
+		t.Errorf("Insert allocates %v times, want only its node", allocs)
+	}
+}
+
+func BenchmarkRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return scapegoat.New[int](0.7) })
+}
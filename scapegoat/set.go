@@ -0,0 +1,77 @@
+package scapegoat
+
+// Merge the sorted nodes a and b, keeping count(x, y) copies of a value found
+// x times in a and y times in b
+func mergeSlices[T any](a, b []*scapeGoatTreeNode[T], cmp func(a, b T) int, count func(x, y int) int) []*scapeGoatTreeNode[T] {
+	result := make([]*scapeGoatTreeNode[T], 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		var value T
+		if len(b) == 0 || (len(a) > 0 && cmp(a[0].value, b[0].value) <= 0) {
+			value = a[0].value
+		} else {
+			value = b[0].value
+		}
+		x, y := equalPrefix(a, value, cmp), equalPrefix(b, value, cmp)
+		if n := count(x, y); n > x {
+			result = append(append(result, a[:x]...), b[:n-x]...)
+		} else {
+			result = append(result, a[:n]...)
+		}
+		a, b = a[x:], b[y:]
+	}
+	return result
+}
+
+// Count the nodes equal to value at the beginning of nodes
+func equalPrefix[T any](nodes []*scapeGoatTreeNode[T], value T, cmp func(a, b T) int) int {
+	n := 0
+	for n < len(nodes) && cmp(nodes[n].value, value) == 0 {
+		n++
+	}
+	return n
+}
+
+func combine[T any](a, b *ScapeGoatTree[T], count func(x, y int) int) *ScapeGoatTree[T] {
+	nodes := mergeSlices(flatten(a.root, nil, false), flatten(b.root, nil, false), a.cmp, count)
+	a.root = nil
+	b.root = nil
+	return &ScapeGoatTree[T]{root: fromSlice(nodes), alpha: a.alpha, cmp: a.cmp, counter: a.counter, options: a.options}
+}
+
+// Union moves the values of a and b to a new tree holding the values of
+// either. A scapegoat tree has no logarithmic join, so both are merged in
+// O(n+m), where n and m are the sizes of the trees. A value is kept as many
+// times as in the tree holding it the most. The new tree is ordered like a,
+// and a and b are left empty.
+func Union[T any](a, b *ScapeGoatTree[T]) *ScapeGoatTree[T] {
+	return combine(a, b, func(x, y int) int {
+		if x > y {
+			return x
+		}
+		return y
+	})
+}
+
+// Intersection moves the values of a and b to a new tree holding the values
+// of both in O(n+m), see Union. A value is kept as many times as in the tree
+// holding it the least.
+func Intersection[T any](a, b *ScapeGoatTree[T]) *ScapeGoatTree[T] {
+	return combine(a, b, func(x, y int) int {
+		if x < y {
+			return x
+		}
+		return y
+	})
+}
+
+// Difference moves the values of a and b to a new tree holding the values of
+// a that are not in b in O(n+m), see Union. A value is kept as many times as
+// it is in a more than in b.
+func Difference[T any](a, b *ScapeGoatTree[T]) *ScapeGoatTree[T] {
+	return combine(a, b, func(x, y int) int {
+		if x > y {
+			return x - y
+		}
+		return 0
+	})
+}
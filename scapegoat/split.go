@@ -0,0 +1,42 @@
+package scapegoat
+
+import "sort"
+
+// SplitAt moves the values less than value to left and the others to right.
+// A scapegoat tree has no logarithmic join, so both are rebuilt in O(n). t is
+// left empty.
+func (t *ScapeGoatTree[T]) SplitAt(value T) (left, right *ScapeGoatTree[T]) {
+	nodes := flatten(t.root, nil, false)
+	k := sort.Search(len(nodes), func(i int) bool {
+		return t.cmp(nodes[i].value, value) >= 0
+	})
+	return t.splitSlice(nodes, k)
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// O(n), see SplitAt. t is left empty.
+func (t *ScapeGoatTree[T]) SplitRank(k uint) (left, right *ScapeGoatTree[T]) {
+	nodes := flatten(t.root, nil, false)
+	if k > uint(len(nodes)) {
+		k = uint(len(nodes))
+	}
+	return t.splitSlice(nodes, int(k))
+}
+
+func (t *ScapeGoatTree[T]) splitSlice(nodes []*scapeGoatTreeNode[T], k int) (left, right *ScapeGoatTree[T]) {
+	t.root = nil
+	left = &ScapeGoatTree[T]{root: fromSlice(nodes[:k]), alpha: t.alpha, cmp: t.cmp, counter: t.counter, options: t.options}
+	right = &ScapeGoatTree[T]{root: fromSlice(nodes[k:]), alpha: t.alpha, cmp: t.cmp, counter: t.counter, options: t.options}
+	return left, right
+}
+
+// Join moves the values of left and right to a new tree in O(n), see
+// SplitAt. Every value of left must be less than every value of right, or
+// equal to it if the trees are not sets, which is not checked. The new tree
+// is ordered like left, and left and right are left empty.
+func Join[T any](left, right *ScapeGoatTree[T]) *ScapeGoatTree[T] {
+	nodes := append(flatten(left.root, nil, false), flatten(right.root, nil, false)...)
+	left.root = nil
+	right.root = nil
+	return &ScapeGoatTree[T]{root: fromSlice(nodes), alpha: left.alpha, cmp: left.cmp, counter: left.counter, options: left.options}
+}
@@ -0,0 +1,18 @@
+package scapegoat
+
+import "github.com/yanglinshu/bstrees/v2"
+
+// Stats returns the operations performed on t since it has been created or
+// since the last ResetStats, along with the TombstoneRatio of t. The trees
+// made from t, such as the trees it is split into, add to the same counts.
+// Operations are only counted when the module is built with the bstrees_stats
+// tag, and cost nothing otherwise.
+func (t *ScapeGoatTree[T]) Stats() bstrees.Stats {
+	result := t.counter.Stats()
+	result.TombstoneRatio = t.TombstoneRatio()
+	return result
+}
+
+func (t *ScapeGoatTree[T]) ResetStats() {
+	t.counter.Reset()
+}
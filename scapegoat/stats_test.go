@@ -0,0 +1,45 @@
+//go:build bstrees_stats
+
+package scapegoat_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/scapegoat"
+)
+
+func TestStats(t *testing.T) {
+	tree := scapegoat.New[int](0.7)
+	other := scapegoat.New[int](0.7)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		tree.Insert(r.Intn(500))
+		tree.Delete(r.Intn(500))
+	}
+	if stats := other.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() of another tree = %+v, want zero", stats)
+	}
+	stats := tree.Stats()
+	for _, c := range []struct {
+		name  string
+		count uint64
+	}{
+		{"Comparisons", stats.Comparisons},
+		{"Rebuilds", stats.Rebuilds},
+		{"RebuiltNodes", stats.RebuiltNodes},
+	} {
+		if c.count == 0 {
+			t.Errorf("Stats().%s = 0, want > 0", c.name)
+		}
+	}
+	if ratio := tree.TombstoneRatio(); stats.TombstoneRatio != ratio {
+		t.Errorf("Stats().TombstoneRatio = %v, want %v", stats.TombstoneRatio, ratio)
+	}
+	tree.Compact()
+	tree.ResetStats()
+	if stats := tree.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() = %+v after Compact and ResetStats, want zero", stats)
+	}
+}
@@ -0,0 +1,60 @@
+package scapegoat
+
+import "github.com/yanglinshu/bstrees/v2/internal/invariant"
+
+// Check the subtree of root, returns its deepest node and the depth of that
+// node below root
+func validate[T any](root *scapeGoatTreeNode[T], order *invariant.Order[T]) (*scapeGoatTreeNode[T], int, error) {
+	if root == nil {
+		return nil, -1, nil
+	}
+	deepest, depth, err := validate(root.left, order)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := order.Next(root.value); err != nil {
+		return nil, 0, err
+	}
+	right, rightDepth, err := validate(root.right, order)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rightDepth > depth {
+		deepest, depth = right, rightDepth
+	}
+	if deepest == nil {
+		deepest = root
+	}
+	size, weight := uint(0), uint(1)
+	if root.active() {
+		size = 1
+	}
+	for _, child := range []*scapeGoatTreeNode[T]{root.left, root.right} {
+		if child != nil {
+			size += child.size
+			weight += child.weight
+		}
+	}
+	if root.size != size {
+		return nil, 0, invariant.Errorf(root.value, "size is %d, want %d active nodes", root.size, size)
+	}
+	if root.weight != weight {
+		return nil, 0, invariant.Errorf(root.value, "weight is %d, want %d nodes", root.weight, weight)
+	}
+	return deepest, depth + 1, nil
+}
+
+// Validate checks the order of the values, deleted ones included, for every
+// node its cached number of active nodes and of nodes, and that no node is
+// deeper than log_{1/alpha}(n). Returns a *bstrees.InvariantError naming the
+// first node found to break a rule.
+func (t *ScapeGoatTree[T]) Validate() error {
+	deepest, depth, err := validate(t.root, invariant.NewOrder(t.cmp, t.unique))
+	if err != nil || t.root == nil {
+		return err
+	}
+	if limit := maxDepth(t.root.weight, t.alpha); depth > limit {
+		return invariant.Errorf(deepest.value, "is at depth %d, deeper than %d in %d nodes", depth, limit, t.root.weight)
+	}
+	return nil
+}
@@ -1,8 +1,9 @@
 package splay
 
-import "golang.org/x/exp/constraints"
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
 
-func leftRotate[T constraints.Ordered](root *splayNode[T]) *splayNode[T] {
+func leftRotate[T any](root *splayNode[T], counter *stats.Counter) *splayNode[T] {
+	counter.Rotation()
 	right := root.right
 	root.setChild(right.left, true)
 	right.setChild(root, false)
@@ -11,7 +12,8 @@ func leftRotate[T constraints.Ordered](root *splayNode[T]) *splayNode[T] {
 	return right
 }
 
-func rightRotate[T constraints.Ordered](root *splayNode[T]) *splayNode[T] {
+func rightRotate[T any](root *splayNode[T], counter *stats.Counter) *splayNode[T] {
+	counter.Rotation()
 	left := root.left
 	root.setChild(left.right, false)
 	left.setChild(root, true)
@@ -22,14 +24,14 @@ func rightRotate[T constraints.Ordered](root *splayNode[T]) *splayNode[T] {
 
 // Rotate root to its parent
 // After this operation, parent will be the child of root
-func rotateToParent[T constraints.Ordered](root *splayNode[T]) {
+func rotateToParent[T any](root *splayNode[T], counter *stats.Counter) {
 	grandParent := root.parent.parent
 	if root == root.parent.left {
 		// root is left child
-		root = rightRotate(root.parent)
+		root = rightRotate(root.parent, counter)
 	} else {
 		// root is right child
-		root = leftRotate(root.parent)
+		root = leftRotate(root.parent, counter)
 	}
 	if grandParent != nil {
 		if grandParent.left == root.parent {
@@ -44,7 +46,7 @@ func rotateToParent[T constraints.Ordered](root *splayNode[T]) {
 
 // Rotate root to target
 // After this operation, target will be the child of root
-func splayRotate[T constraints.Ordered](root, target *splayNode[T]) {
+func splayRotate[T any](root, target *splayNode[T], counter *stats.Counter) {
 	targetParent := target.parent
 	for root.parent != targetParent {
 		parent := root.parent
@@ -53,15 +55,40 @@ func splayRotate[T constraints.Ordered](root, target *splayNode[T]) {
 		grandDirection := parent == grandParent.left
 		if parent == target {
 			// root is the child of target
-			rotateToParent(root)
+			counter.Zig()
+			rotateToParent(root, counter)
 		} else if direction == grandDirection {
 			// zig-zig
-			rotateToParent(parent)
-			rotateToParent(root)
+			counter.ZigZig()
+			rotateToParent(parent, counter)
+			rotateToParent(root, counter)
 		} else {
 			// zig-zag
-			rotateToParent(root)
-			rotateToParent(root)
+			counter.ZigZag()
+			rotateToParent(root, counter)
+			rotateToParent(root, counter)
 		}
 	}
 }
+
+// Build a balanced tree from sorted values, in which equal values are
+// counted by a single node.
+func fromSorted[T any](values []T, cmp func(a, b T) int) *splayNode[T] {
+	if len(values) == 0 {
+		return nil
+	}
+	mid := len(values) / 2
+	first, last := mid, mid+1
+	for first > 0 && cmp(values[first-1], values[mid]) == 0 {
+		first--
+	}
+	for last < len(values) && cmp(values[last], values[mid]) == 0 {
+		last++
+	}
+	root := newSplayNode(values[mid])
+	root.rec = uint(last - first)
+	root.setChild(fromSorted(values[:first], cmp), false)
+	root.setChild(fromSorted(values[last:], cmp), true)
+	root.update()
+	return root
+}
@@ -0,0 +1,200 @@
+package splay
+
+import (
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/invariant"
+)
+
+// Append the nodes of root in pre-order
+func encodeNodes[T any](root *splayNode[T], nodes []codec.Node[T]) []codec.Node[T] {
+	if root == nil {
+		return nodes
+	}
+	nodes = append(nodes, codec.Node[T]{Value: root.value, Left: root.left != nil, Right: root.right != nil, Count: root.rec})
+	nodes = encodeNodes(root.left, nodes)
+	return encodeNodes(root.right, nodes)
+}
+
+// Rebuild a node from its serialized form
+func decodeNode[T any](n *codec.Node[T], left, right *splayNode[T]) *splayNode[T] {
+	node := newSplayNode(n.Value)
+	node.rec = n.Count
+	node.setChild(left, false)
+	node.setChild(right, true)
+	node.update()
+	return node
+}
+
+func (t *Splay[T]) encode() *codec.Tree[T] {
+	tree := new(codec.Tree[T])
+	if t.shape {
+		tree.Nodes = encodeNodes(t.root(), make([]codec.Node[T], 0, t.Size()))
+	} else {
+		tree.Values = make([]T, 0, t.Size())
+		ascend(t.root(), func(value T) bool {
+			tree.Values = append(tree.Values, value)
+			return true
+		})
+	}
+	return tree
+}
+
+func (t *Splay[T]) decode(tree *codec.Tree[T]) error {
+	if t.cmp == nil {
+		return bstrees.ErrTreeIsNotInitialized
+	}
+	if len(tree.Nodes) != 0 {
+		root, err := codec.Build(tree.Nodes, decodeNode[T])
+		if err != nil {
+			return err
+		}
+		if err := validate(root, invariant.NewOrder(t.cmp, true)); err != nil {
+			return codec.Corrupted(err)
+		}
+		t.setRoot(root)
+		return nil
+	}
+	if !codec.Sorted(tree.Values, t.cmp, false) {
+		return bstrees.ErrDataIsCorrupted
+	}
+	values := tree.Values
+	if t.unique {
+		values = codec.Unique(values, t.cmp)
+	}
+	t.setRoot(fromSorted(values, t.cmp))
+	return nil
+}
+
+// MarshalBinary encodes the values of t in ascending order, or its nodes with
+// the number of copies they hold if t has been created with PreserveShape.
+func (t *Splay[T]) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(t.encode())
+}
+
+// UnmarshalBinary replaces the values of t with the ones encoded by
+// MarshalBinary, in O(n) whichever the encoding. t must have been created by a
+// constructor, and keeps its own options.
+func (t *Splay[T]) UnmarshalBinary(data []byte) error {
+	tree, err := codec.UnmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+// MarshalJSON encodes t like MarshalBinary, as a JSON object.
+func (t *Splay[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(t.encode())
+}
+
+// UnmarshalJSON decodes t like UnmarshalBinary.
+func (t *Splay[T]) UnmarshalJSON(data []byte) error {
+	tree, err := codec.UnmarshalJSON[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+func (t *Splay[T]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+func (t *Splay[T]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
+
+// Append the nodes of n in pre-order
+func (n *topDownNode[T]) encode(nodes []codec.Node[T]) []codec.Node[T] {
+	if n == nil {
+		return nodes
+	}
+	nodes = append(nodes, codec.Node[T]{Value: n.value, Left: n.left != nil, Right: n.right != nil, Count: n.rec})
+	nodes = n.left.encode(nodes)
+	return n.right.encode(nodes)
+}
+
+// Rebuild a node of a TopDown tree from its serialized form
+func decodeTopDownNode[T any](n *codec.Node[T], left, right *topDownNode[T]) *topDownNode[T] {
+	node := &topDownNode[T]{value: n.Value, left: left, right: right, rec: n.Count}
+	node.update()
+	return node
+}
+
+func (t *TopDown[T]) encode() *codec.Tree[T] {
+	tree := new(codec.Tree[T])
+	if t.shape {
+		tree.Nodes = t.root.encode(make([]codec.Node[T], 0, t.Size()))
+	} else {
+		tree.Values = make([]T, 0, t.Size())
+		t.root.ascend(func(value T) bool {
+			tree.Values = append(tree.Values, value)
+			return true
+		})
+	}
+	return tree
+}
+
+func (t *TopDown[T]) decode(tree *codec.Tree[T]) error {
+	if t.cmp == nil {
+		return bstrees.ErrTreeIsNotInitialized
+	}
+	if len(tree.Nodes) != 0 {
+		root, err := codec.Build(tree.Nodes, decodeTopDownNode[T])
+		if err != nil {
+			return err
+		}
+		if err := root.validate(invariant.NewOrder(t.cmp, true)); err != nil {
+			return codec.Corrupted(err)
+		}
+		t.root = root
+		return nil
+	}
+	if !codec.Sorted(tree.Values, t.cmp, false) {
+		return bstrees.ErrDataIsCorrupted
+	}
+	values := tree.Values
+	if t.unique {
+		values = codec.Unique(values, t.cmp)
+	}
+	t.root = topDownFromSorted(values, t.cmp)
+	return nil
+}
+
+// MarshalBinary encodes t like Splay.MarshalBinary, in the same format.
+func (t *TopDown[T]) MarshalBinary() ([]byte, error) {
+	return codec.MarshalBinary(t.encode())
+}
+
+// UnmarshalBinary replaces the values of t with the ones encoded by
+// MarshalBinary, see Splay.UnmarshalBinary.
+func (t *TopDown[T]) UnmarshalBinary(data []byte) error {
+	tree, err := codec.UnmarshalBinary[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+// MarshalJSON encodes t like MarshalBinary, as a JSON object.
+func (t *TopDown[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalJSON(t.encode())
+}
+
+// UnmarshalJSON decodes t like UnmarshalBinary.
+func (t *TopDown[T]) UnmarshalJSON(data []byte) error {
+	tree, err := codec.UnmarshalJSON[T](data)
+	if err != nil {
+		return err
+	}
+	return t.decode(tree)
+}
+
+func (t *TopDown[T]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+func (t *TopDown[T]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
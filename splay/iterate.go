@@ -0,0 +1,185 @@
+package splay
+
+// Iterate over root in order, stop when fn returns false.
+// Returns false if the iteration has been stopped.
+func ascend[T any](root *splayNode[T], fn func(T) bool) bool {
+	if root == nil {
+		return true
+	}
+	if !ascend(root.left, fn) {
+		return false
+	}
+	for i := uint(0); i < root.rec; i++ {
+		if !fn(root.value) {
+			return false
+		}
+	}
+	return ascend(root.right, fn)
+}
+
+func descend[T any](root *splayNode[T], fn func(T) bool) bool {
+	if root == nil {
+		return true
+	}
+	if !descend(root.right, fn) {
+		return false
+	}
+	for i := uint(0); i < root.rec; i++ {
+		if !fn(root.value) {
+			return false
+		}
+	}
+	return descend(root.left, fn)
+}
+
+// Iterate over values in [lo, hi) in order
+func ascendRange[T any](root *splayNode[T], lo, hi T, fn func(T) bool, cmp func(a, b T) int) bool {
+	if root == nil {
+		return true
+	}
+	afterLo := cmp(root.value, lo) >= 0
+	beforeHi := cmp(root.value, hi) < 0
+	if afterLo && !ascendRange(root.left, lo, hi, fn, cmp) {
+		return false
+	}
+	if afterLo && beforeHi {
+		for i := uint(0); i < root.rec; i++ {
+			if !fn(root.value) {
+				return false
+			}
+		}
+	}
+	return !beforeHi || ascendRange(root.right, lo, hi, fn, cmp)
+}
+
+// Iterate over values in (lo, hi] in reverse order
+func descendRange[T any](root *splayNode[T], hi, lo T, fn func(T) bool, cmp func(a, b T) int) bool {
+	if root == nil {
+		return true
+	}
+	afterLo := cmp(root.value, lo) > 0
+	beforeHi := cmp(root.value, hi) <= 0
+	if beforeHi && !descendRange(root.right, hi, lo, fn, cmp) {
+		return false
+	}
+	if afterLo && beforeHi {
+		for i := uint(0); i < root.rec; i++ {
+			if !fn(root.value) {
+				return false
+			}
+		}
+	}
+	return !afterLo || descendRange(root.left, hi, lo, fn, cmp)
+}
+
+// Ascend calls fn for every value in ascending order until fn returns false.
+func (t *Splay[T]) Ascend(fn func(value T) bool) {
+	ascend(t.root(), fn)
+}
+
+// Descend calls fn for every value in descending order until fn returns false.
+func (t *Splay[T]) Descend(fn func(value T) bool) {
+	descend(t.root(), fn)
+}
+
+// AscendRange calls fn for every value in [greaterOrEqual, lessThan) in
+// ascending order until fn returns false.
+func (t *Splay[T]) AscendRange(greaterOrEqual, lessThan T, fn func(value T) bool) {
+	ascendRange(t.root(), greaterOrEqual, lessThan, fn, t.cmp)
+}
+
+// DescendRange calls fn for every value in (greaterThan, lessOrEqual] in
+// descending order until fn returns false.
+func (t *Splay[T]) DescendRange(lessOrEqual, greaterThan T, fn func(value T) bool) {
+	descendRange(t.root(), lessOrEqual, greaterThan, fn, t.cmp)
+}
+
+func (n *topDownNode[T]) ascend(fn func(T) bool) bool {
+	if n == nil {
+		return true
+	}
+	if !n.left.ascend(fn) {
+		return false
+	}
+	for i := uint(0); i < n.rec; i++ {
+		if !fn(n.value) {
+			return false
+		}
+	}
+	return n.right.ascend(fn)
+}
+
+func (n *topDownNode[T]) descend(fn func(T) bool) bool {
+	if n == nil {
+		return true
+	}
+	if !n.right.descend(fn) {
+		return false
+	}
+	for i := uint(0); i < n.rec; i++ {
+		if !fn(n.value) {
+			return false
+		}
+	}
+	return n.left.descend(fn)
+}
+
+func (n *topDownNode[T]) ascendRange(lo, hi T, fn func(T) bool, cmp func(a, b T) int) bool {
+	if n == nil {
+		return true
+	}
+	afterLo := cmp(n.value, lo) >= 0
+	beforeHi := cmp(n.value, hi) < 0
+	if afterLo && !n.left.ascendRange(lo, hi, fn, cmp) {
+		return false
+	}
+	if afterLo && beforeHi {
+		for i := uint(0); i < n.rec; i++ {
+			if !fn(n.value) {
+				return false
+			}
+		}
+	}
+	return !beforeHi || n.right.ascendRange(lo, hi, fn, cmp)
+}
+
+func (n *topDownNode[T]) descendRange(hi, lo T, fn func(T) bool, cmp func(a, b T) int) bool {
+	if n == nil {
+		return true
+	}
+	afterLo := cmp(n.value, lo) > 0
+	beforeHi := cmp(n.value, hi) <= 0
+	if beforeHi && !n.right.descendRange(hi, lo, fn, cmp) {
+		return false
+	}
+	if afterLo && beforeHi {
+		for i := uint(0); i < n.rec; i++ {
+			if !fn(n.value) {
+				return false
+			}
+		}
+	}
+	return !afterLo || n.left.descendRange(hi, lo, fn, cmp)
+}
+
+// Ascend calls fn for every value in ascending order until fn returns false.
+func (t *TopDown[T]) Ascend(fn func(value T) bool) {
+	t.root.ascend(fn)
+}
+
+// Descend calls fn for every value in descending order until fn returns false.
+func (t *TopDown[T]) Descend(fn func(value T) bool) {
+	t.root.descend(fn)
+}
+
+// AscendRange calls fn for every value in [greaterOrEqual, lessThan) in
+// ascending order until fn returns false.
+func (t *TopDown[T]) AscendRange(greaterOrEqual, lessThan T, fn func(value T) bool) {
+	t.root.ascendRange(greaterOrEqual, lessThan, fn, t.cmp)
+}
+
+// DescendRange calls fn for every value in (greaterThan, lessOrEqual] in
+// descending order until fn returns false.
+func (t *TopDown[T]) DescendRange(lessOrEqual, greaterThan T, fn func(value T) bool) {
+	t.root.descendRange(lessOrEqual, greaterThan, fn, t.cmp)
+}
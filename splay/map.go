@@ -0,0 +1,96 @@
+package splay
+
+import (
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/ordmap"
+	"golang.org/x/exp/constraints"
+)
+
+var _ bstrees.Map[int, int] = (*Map[int, int])(nil)
+
+// Map is an ordered map stored in a Splay tree, with at most one value per key.
+// Map must be created by NewMap or NewMapFunc.
+type Map[K, V any] struct {
+	ordmap.Map[K, V]
+}
+
+func NewMap[K constraints.Ordered, V any]() *Map[K, V] {
+	return NewMapFunc[K, V](bstrees.Compare[K])
+}
+
+// NewMapFunc creates a map ordered by cmp, see NewFunc.
+func NewMapFunc[K, V any](cmp func(a, b K) int) *Map[K, V] {
+	tree := NewFunc(func(a, b ordmap.Entry[K, V]) int {
+		return cmp(a.Key, b.Key)
+	}, Unique())
+	return &Map[K, V]{ordmap.New[K, V](mapTree[K, V]{tree})}
+}
+
+type mapTree[K, V any] struct {
+	*Splay[ordmap.Entry[K, V]]
+}
+
+func (t mapTree[K, V]) Search(key K) *ordmap.Entry[K, V] {
+	found, last := lookup(t.root(), ordmap.Entry[K, V]{Key: key}, t.cmp)
+	t.touch(last)
+	if found != nil {
+		return &found.value
+	}
+	return nil
+}
+
+// The node holding key is splayed to the root
+func (t mapTree[K, V]) Upsert(key K) (*ordmap.Entry[K, V], bool) {
+	root, inserted := insert(t.root(), ordmap.Entry[K, V]{Key: key}, t.cmp, true, t.counter)
+	t.setRoot(root)
+	return &root.value, !inserted
+}
+
+func (t mapTree[K, V]) Remove(key K) bool {
+	root, deleted := delete(t.root(), ordmap.Entry[K, V]{Key: key}, t.cmp, t.counter)
+	t.setRoot(root)
+	return deleted
+}
+
+var _ bstrees.Map[int, int] = (*TopDownMap[int, int])(nil)
+
+// TopDownMap is an ordered map stored in a TopDown tree, with at most one
+// value per key. TopDownMap must be created by NewTopDownMap or
+// NewTopDownMapFunc.
+type TopDownMap[K, V any] struct {
+	ordmap.Map[K, V]
+}
+
+func NewTopDownMap[K constraints.Ordered, V any]() *TopDownMap[K, V] {
+	return NewTopDownMapFunc[K, V](bstrees.Compare[K])
+}
+
+// NewTopDownMapFunc creates a map ordered by cmp, see NewFunc.
+func NewTopDownMapFunc[K, V any](cmp func(a, b K) int) *TopDownMap[K, V] {
+	tree := NewTopDownFunc(func(a, b ordmap.Entry[K, V]) int {
+		return cmp(a.Key, b.Key)
+	}, Unique())
+	return &TopDownMap[K, V]{ordmap.New[K, V](topDownMapTree[K, V]{tree})}
+}
+
+type topDownMapTree[K, V any] struct {
+	*TopDown[ordmap.Entry[K, V]]
+}
+
+func (t topDownMapTree[K, V]) Search(key K) *ordmap.Entry[K, V] {
+	value := ordmap.Entry[K, V]{Key: key}
+	if p := t.search(t.toward(value)); p != nil && t.cmp(value, p.value) == 0 {
+		return &p.value
+	}
+	return nil
+}
+
+// Insert leaves the node holding key at the root
+func (t topDownMapTree[K, V]) Upsert(key K) (*ordmap.Entry[K, V], bool) {
+	inserted := t.Insert(ordmap.Entry[K, V]{Key: key})
+	return &t.root.value, !inserted
+}
+
+func (t topDownMapTree[K, V]) Remove(key K) bool {
+	return t.delete(ordmap.Entry[K, V]{Key: key})
+}
@@ -1,8 +1,6 @@
 package splay
 
-import "golang.org/x/exp/constraints"
-
-type splayNode[T constraints.Ordered] struct {
+type splayNode[T any] struct {
 	value  T
 	left   *splayNode[T]
 	right  *splayNode[T]
@@ -13,7 +11,7 @@ type splayNode[T constraints.Ordered] struct {
 	// While traditional BST search mechanics is too slow on Splay
 }
 
-func newSplayNode[T constraints.Ordered](value T) *splayNode[T] {
+func newSplayNode[T any](value T) *splayNode[T] {
 	return &splayNode[T]{
 		value:  value,
 		left:   nil,
@@ -44,3 +42,34 @@ func (n *splayNode[T]) setChild(child *splayNode[T], direction bool) {
 		child.parent = n
 	}
 }
+
+// Node of a TopDown tree, which needs no parent link
+type topDownNode[T any] struct {
+	value T
+	left  *topDownNode[T]
+	right *topDownNode[T]
+	size  uint
+	rec   uint
+}
+
+func newTopDownNode[T any](value T) *topDownNode[T] {
+	return &topDownNode[T]{
+		value: value,
+		left:  nil,
+		right: nil,
+		size:  1,
+		rec:   1,
+	}
+}
+
+// Size of the subtree of n, which may be nil
+func sizeOf[T any](n *topDownNode[T]) uint {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *topDownNode[T]) update() {
+	n.size = n.rec + sizeOf(n.left) + sizeOf(n.right)
+}
@@ -0,0 +1,52 @@
+package splay
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yanglinshu/bstrees/v2/internal/render"
+)
+
+func describe[T any](root *splayNode[T]) *render.Node {
+	if root == nil {
+		return nil
+	}
+	n := &render.Node{Value: fmt.Sprint(root.value), Meta: fmt.Sprintf("rec=%d", root.rec)}
+	n.Left = describe(root.left)
+	n.Right = describe(root.right)
+	return n
+}
+
+// WriteDOT writes the structure of t as a Graphviz digraph, each node being
+// labeled with the number of copies it holds.
+func (t *Splay[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, describe(t.root()))
+}
+
+// String draws the structure of t as text, see WriteDOT.
+func (t *Splay[T]) String() string {
+	return render.String(describe(t.root()))
+}
+
+func (n *topDownNode[T]) describe() *render.Node {
+	if n == nil {
+		return nil
+	}
+	return &render.Node{
+		Value: fmt.Sprint(n.value),
+		Meta:  fmt.Sprintf("rec=%d", n.rec),
+		Left:  n.left.describe(),
+		Right: n.right.describe(),
+	}
+}
+
+// WriteDOT writes the structure of t as a Graphviz digraph, each node being
+// labeled with the number of copies it holds.
+func (t *TopDown[T]) WriteDOT(w io.Writer) error {
+	return render.WriteDOT(w, t.root.describe())
+}
+
+// String draws the structure of t as text, see WriteDOT.
+func (t *TopDown[T]) String() string {
+	return render.String(t.root.describe())
+}
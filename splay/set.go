@@ -0,0 +1,270 @@
+package splay
+
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
+
+// Collect the nodes of root in order
+func toSlice[T any](root *splayNode[T], nodes []*splayNode[T]) []*splayNode[T] {
+	if root == nil {
+		return nodes
+	}
+	nodes = toSlice(root.left, nodes)
+	nodes = append(nodes, root)
+	return toSlice(root.right, nodes)
+}
+
+// Splay p to the root of the subtree root, under top which stands for the
+// super root while root is apart from any tree
+func splayUnder[T any](p, root, top *splayNode[T], counter *stats.Counter) {
+	top.setChild(root, true)
+	splayRotate(p, root, counter)
+	p.parent = nil
+	top.right = nil
+}
+
+// Split root into the values less than value, the node holding value and
+// the values greater than it, with the least value not less than value
+// splayed first
+func split3[T any](root, top *splayNode[T], value T, cmp func(a, b T) int, counter *stats.Counter) (less, equal, greater *splayNode[T]) {
+	if root == nil {
+		return nil, nil, nil
+	}
+	p := ceiling(root, value, cmp)
+	if p == nil {
+		return root, nil, nil
+	}
+	splayUnder(p, root, top, counter)
+	less, p.left = p.left, nil
+	if less != nil {
+		less.parent = nil
+	}
+	if cmp(p.value, value) != 0 {
+		p.update()
+		return less, nil, p
+	}
+	greater, p.right = p.right, nil
+	if greater != nil {
+		greater.parent = nil
+	}
+	p.update()
+	return less, p, greater
+}
+
+// Join left and right, the values of left being less than those of right
+func join2[T any](left, right, top *splayNode[T], counter *stats.Counter) *splayNode[T] {
+	if left == nil {
+		return right
+	}
+	p := maximum(left)
+	splayUnder(p, left, top, counter)
+	p.setChild(right, true)
+	p.update()
+	return p
+}
+
+// Combine the sorted nodes of the smaller tree with the larger tree root by
+// splitting root around the middle node, keeping count(x, y) copies of a
+// value found x times among nodes and y times in root
+func combineSplit[T any](nodes []*splayNode[T], root, top *splayNode[T], cmp func(a, b T) int, count func(x, y uint) uint, counter *stats.Counter) *splayNode[T] {
+	if len(nodes) == 0 {
+		if count(0, 1) == 0 {
+			return nil
+		}
+		return root
+	}
+	if root == nil && count(1, 0) == 0 {
+		return nil
+	}
+	mid := len(nodes) / 2
+	node := nodes[mid]
+	less, equal, greater := split3(root, top, node.value, cmp, counter)
+	left := combineSplit(nodes[:mid], less, top, cmp, count, counter)
+	right := combineSplit(nodes[mid+1:], greater, top, cmp, count, counter)
+	y := uint(0)
+	if equal != nil {
+		y = equal.rec
+	}
+	if node.rec = count(node.rec, y); node.rec == 0 {
+		return join2(left, right, top, counter)
+	}
+	node.parent = nil
+	node.setChild(left, false)
+	node.setChild(right, true)
+	node.update()
+	return node
+}
+
+// Flatten the smaller of a and b, and split the larger around its values
+func combine[T any](a, b *Splay[T], count func(x, y uint) uint) *Splay[T] {
+	aRoot, bRoot, smaller := a.root(), b.root(), a.Size() <= b.Size()
+	a.setRoot(nil)
+	b.setRoot(nil)
+	if aRoot != nil {
+		aRoot.parent = nil
+	}
+	if bRoot != nil {
+		bRoot.parent = nil
+	}
+	var root *splayNode[T]
+	if smaller {
+		root = combineSplit(toSlice(aRoot, nil), bRoot, a.superRoot, a.cmp, count, a.counter)
+	} else {
+		root = combineSplit(toSlice(bRoot, nil), aRoot, a.superRoot, a.cmp, func(x, y uint) uint {
+			return count(y, x)
+		}, a.counter)
+	}
+	return a.with(root)
+}
+
+// Union moves the values of a and b to a new tree holding the values of
+// either in amortized O(m log(n/m+1)), where m <= n are the sizes of the
+// trees: the smaller tree is flattened and the larger one split around its
+// values. A value is kept as many times as in the tree holding it the most.
+// The new tree is ordered like a, and a and b are left empty.
+func Union[T any](a, b *Splay[T]) *Splay[T] {
+	return combine(a, b, most)
+}
+
+// Intersection moves the values of a and b to a new tree holding the values
+// of both in amortized O(m log(n/m+1)), see Union. A value is kept as many
+// times as in the tree holding it the least.
+func Intersection[T any](a, b *Splay[T]) *Splay[T] {
+	return combine(a, b, least)
+}
+
+// Difference moves the values of a and b to a new tree holding the values of
+// a that are not in b in amortized O(m log(n/m+1)), see Union. A value is
+// kept as many times as it is in a more than in b.
+func Difference[T any](a, b *Splay[T]) *Splay[T] {
+	return combine(a, b, excess)
+}
+
+// Copies kept by Union, Intersection and Difference of a value found x times
+// in a and y times in b
+func most(x, y uint) uint {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+func least(x, y uint) uint {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func excess(x, y uint) uint {
+	if x > y {
+		return x - y
+	}
+	return 0
+}
+
+// Same as toSlice, in a TopDown tree
+func toSliceTopDown[T any](root *topDownNode[T], nodes []*topDownNode[T]) []*topDownNode[T] {
+	if root == nil {
+		return nodes
+	}
+	nodes = toSliceTopDown(root.left, nodes)
+	nodes = append(nodes, root)
+	return toSliceTopDown(root.right, nodes)
+}
+
+// Same as split3, in a TopDown tree
+func split3TopDown[T any](root *topDownNode[T], value T, cmp func(a, b T) int, counter *stats.Counter) (less, equal, greater *topDownNode[T]) {
+	root = splayTopDown(root, func(n *topDownNode[T]) int {
+		return cmp(value, n.value)
+	}, counter)
+	if root == nil {
+		return nil, nil, nil
+	}
+	if c := cmp(value, root.value); c < 0 {
+		// root holds the least value greater than value
+		less, root.left = root.left, nil
+		root.update()
+		return less, nil, root
+	} else if c > 0 {
+		// root holds the greatest value less than value
+		greater, root.right = root.right, nil
+		root.update()
+		return root, nil, greater
+	}
+	less, greater = root.left, root.right
+	root.left, root.right = nil, nil
+	root.update()
+	return less, root, greater
+}
+
+// Same as join2, in a TopDown tree
+func join2TopDown[T any](left, right *topDownNode[T], counter *stats.Counter) *topDownNode[T] {
+	if left == nil {
+		return right
+	}
+	// The greatest node of left has no right child once splayed
+	p := splayTopDown(left, toEnd[T](true), counter)
+	p.right = right
+	p.update()
+	return p
+}
+
+// Same as combineSplit, in a TopDown tree
+func combineSplitTopDown[T any](nodes []*topDownNode[T], root *topDownNode[T], cmp func(a, b T) int, count func(x, y uint) uint, counter *stats.Counter) *topDownNode[T] {
+	if len(nodes) == 0 {
+		if count(0, 1) == 0 {
+			return nil
+		}
+		return root
+	}
+	if root == nil && count(1, 0) == 0 {
+		return nil
+	}
+	mid := len(nodes) / 2
+	node := nodes[mid]
+	less, equal, greater := split3TopDown(root, node.value, cmp, counter)
+	left := combineSplitTopDown(nodes[:mid], less, cmp, count, counter)
+	right := combineSplitTopDown(nodes[mid+1:], greater, cmp, count, counter)
+	y := uint(0)
+	if equal != nil {
+		y = equal.rec
+	}
+	if node.rec = count(node.rec, y); node.rec == 0 {
+		return join2TopDown(left, right, counter)
+	}
+	node.left, node.right = left, right
+	node.update()
+	return node
+}
+
+// Same as combine, for TopDown trees
+func combineTopDown[T any](a, b *TopDown[T], count func(x, y uint) uint) *TopDown[T] {
+	aRoot, bRoot, smaller := a.root, b.root, a.Size() <= b.Size()
+	a.root, b.root = nil, nil
+	var root *topDownNode[T]
+	if smaller {
+		root = combineSplitTopDown(toSliceTopDown(aRoot, nil), bRoot, a.cmp, count, a.counter)
+	} else {
+		root = combineSplitTopDown(toSliceTopDown(bRoot, nil), aRoot, a.cmp, func(x, y uint) uint {
+			return count(y, x)
+		}, a.counter)
+	}
+	return a.with(root)
+}
+
+// UnionTopDown moves the values of a and b to a new tree in amortized
+// O(m log(n/m+1)), see Union. a and b are left empty.
+func UnionTopDown[T any](a, b *TopDown[T]) *TopDown[T] {
+	return combineTopDown(a, b, most)
+}
+
+// IntersectionTopDown moves the values of a and b to a new tree in amortized
+// O(m log(n/m+1)), see Intersection. a and b are left empty.
+func IntersectionTopDown[T any](a, b *TopDown[T]) *TopDown[T] {
+	return combineTopDown(a, b, least)
+}
+
+// DifferenceTopDown moves the values of a and b to a new tree in amortized
+// O(m log(n/m+1)), see Difference. a and b are left empty.
+func DifferenceTopDown[T any](a, b *TopDown[T]) *TopDown[T] {
+	return combineTopDown(a, b, excess)
+}
@@ -2,11 +2,19 @@ package splay
 
 import (
 	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
 	"golang.org/x/exp/constraints"
 )
 
-type Splay[T constraints.Ordered] struct {
+var _ bstrees.Tree[int] = (*Splay[int])(nil)
+
+// Splay must be created by a constructor such as New or NewFunc.
+type Splay[T any] struct {
 	superRoot *splayNode[T]
+	cmp       func(a, b T) int
+	options
+	counter *stats.Counter // Shared with the trees made from this one
 }
 
 func (t *Splay[T]) root() *splayNode[T] {
@@ -17,17 +25,45 @@ func (t *Splay[T]) setRoot(root *splayNode[T]) {
 	t.superRoot.setChild(root, true)
 }
 
-func New[T constraints.Ordered]() *Splay[T] {
+func New[T constraints.Ordered](opts ...Option) *Splay[T] {
+	return NewFunc(bstrees.Compare[T], opts...)
+}
+
+// NewFunc creates a tree ordered by cmp, which returns a negative number when
+// a < b, a positive number when a > b and zero when a == b.
+func NewFunc[T any](cmp func(a, b T) int, opts ...Option) *Splay[T] {
+	var zero T
+	counter := new(stats.Counter)
 	return &Splay[T]{
-		superRoot: newSplayNode(T(rune(0))),
+		superRoot: newSplayNode(zero),
+		cmp:       stats.Compare(counter, cmp),
+		options:   newOptions(opts),
+		counter:   counter,
+	}
+}
+
+// FromSorted builds a tree from values sorted in ascending order in O(n).
+// The order of values is not checked.
+func FromSorted[T constraints.Ordered](values []T, opts ...Option) *Splay[T] {
+	return FromSortedFunc(bstrees.Compare[T], values, opts...)
+}
+
+// FromSortedFunc builds a tree ordered by cmp from values sorted by cmp in
+// O(n), see NewFunc and FromSorted.
+func FromSortedFunc[T any](cmp func(a, b T) int, values []T, opts ...Option) *Splay[T] {
+	t := NewFunc(cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
 	}
+	t.setRoot(fromSorted(values, cmp))
+	return t
 }
 
-func search[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T] {
+func search[T any](root *splayNode[T], value T, cmp func(a, b T) int) *splayNode[T] {
 	for p := root; p != nil; {
-		if p.value == value {
+		if c := cmp(value, p.value); c == 0 {
 			return p
-		} else if value < p.value {
+		} else if c < 0 {
 			p = p.left
 		} else {
 			p = p.right
@@ -36,14 +72,66 @@ func search[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T] {
 	return nil
 }
 
-func at[T constraints.Ordered](root *splayNode[T], k uint) *splayNode[T] {
+// Find the node of value, also returns the last node visited, which is the
+// node found if value is present
+func lookup[T any](root *splayNode[T], value T, cmp func(a, b T) int) (found, last *splayNode[T]) {
+	for p := root; p != nil; {
+		last = p
+		if c := cmp(value, p.value); c == 0 {
+			return p, p
+		} else if c < 0 {
+			p = p.left
+		} else {
+			p = p.right
+		}
+	}
+	return nil, last
+}
+
+// Splay p to the root after a read, unless reads do not splay
+func (t *Splay[T]) touch(p *splayNode[T]) {
+	if p != nil && !t.noReadSplay {
+		splayRotate(p, t.root(), t.counter)
+	}
+}
+
+// Splay the smallest node, or the greatest one if greatest is set, after a
+// read that has found nothing on its way to it
+func (t *Splay[T]) touchEnd(greatest bool) {
+	if t.noReadSplay || t.root() == nil {
+		return
+	}
+	if greatest {
+		splayRotate(maximum(t.root()), t.root(), t.counter)
+	} else {
+		splayRotate(minimum(t.root()), t.root(), t.counter)
+	}
+}
+
+// Splay moves the node holding value to the root, or the last node visited
+// looking for it if value is absent, and reports whether value is present.
+// It splays even if the tree has been created with NoReadSplay.
+func (t *Splay[T]) Splay(value T) bool {
+	found, last := lookup(t.root(), value, t.cmp)
+	if last != nil {
+		splayRotate(last, t.root(), t.counter)
+	}
+	return found != nil
+}
+
+// SplaysReads reports whether the reads of t splay the node they reach, that
+// is whether t has been created without NoReadSplay.
+func (t *Splay[T]) SplaysReads() bool {
+	return !t.noReadSplay
+}
+
+func at[T any](root *splayNode[T], k uint) *splayNode[T] {
 	for p := root; p != nil; {
 		leftSize := uint(0)
 		if p.left != nil {
 			leftSize = p.left.size
 		}
 		if leftSize < k && leftSize+p.rec >= k {
-			// SplayRotate(p, root)
 			return p
 		} else if leftSize+p.rec < k {
 			k -= leftSize + p.rec
@@ -55,51 +143,58 @@ func at[T constraints.Ordered](root *splayNode[T], k uint) *splayNode[T] {
 	return nil
 }
 
-func insert[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T] {
+// Insert value into root, unless unique is set and value is already present.
+// Returns the new root and whether value has been inserted.
+func insert[T any](root *splayNode[T], value T, cmp func(a, b T) int, unique bool, counter *stats.Counter) (*splayNode[T], bool) {
 	if root == nil {
-		return newSplayNode(value)
+		return newSplayNode(value), true
 	} else {
 		superRoot := root.parent
 
-		for p := root; p != nil; {
-			p.size += 1
-			if value == p.value {
-				p.rec += 1
-				splayRotate(p, root)
-				break
-			} else if value < p.value {
-				if p.left == nil {
-					p.setChild(newSplayNode(value), false)
-					splayRotate(p.left, root)
-					break
-				} else {
-					p = p.left
-				}
+		p := root
+		c := cmp(value, p.value)
+		for c < 0 && p.left != nil || c > 0 && p.right != nil {
+			if c < 0 {
+				p = p.left
 			} else {
-				if p.right == nil {
-					p.setChild(newSplayNode(value), true)
-					splayRotate(p.right, root)
-					break
-				} else {
-					p = p.right
-				}
+				p = p.right
 			}
+			c = cmp(value, p.value)
+		}
+		if c == 0 && unique {
+			splayRotate(p, root, counter)
+			return superRoot.right, false
 		}
 
-		return superRoot.right
+		// Value goes into p or under it, so the sizes of p and the nodes
+		// above it only grow now
+		for q := p; q != superRoot; q = q.parent {
+			q.size += 1
+		}
+		if c == 0 {
+			p.rec += 1
+		} else {
+			node := newSplayNode(value)
+			p.setChild(node, c > 0)
+			p = node
+		}
+		splayRotate(p, root, counter)
+		return superRoot.right, true
 	}
 }
 
-func delete[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T] {
+// Delete one copy of value from root, returns the new root and whether value
+// has been deleted
+func delete[T any](root *splayNode[T], value T, cmp func(a, b T) int, counter *stats.Counter) (*splayNode[T], bool) {
 	if root == nil {
-		return nil
+		return nil, false
 	}
 	superRoot := root.parent
-	p := search(root, value)
+	p := search(root, value, cmp)
 	if p == nil {
-		return root
+		return root, false
 	}
-	splayRotate(p, root)
+	splayRotate(p, root, counter)
 	if p.rec > 1 {
 		p.rec -= 1
 		p.size -= 1
@@ -116,33 +211,40 @@ func delete[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T] {
 				maxLeft.size -= 1
 				maxLeft = maxLeft.right
 			}
-			splayRotate(maxLeft, superRoot.right)
+			splayRotate(maxLeft, superRoot.right, counter)
 			maxLeft.setChild(p.right, true)
 			superRoot.setChild(maxLeft, true)
 			superRoot.right.update()
 		}
 	}
 
-	return superRoot.right
+	return superRoot.right, true
 }
 
-func (t *Splay[T]) Insert(value T) {
-	t.setRoot(insert(t.root(), value))
+func (t *Splay[T]) Insert(value T) bool {
+	root, inserted := insert(t.root(), value, t.cmp, t.unique, t.counter)
+	t.setRoot(root)
+	return inserted
 }
 
 func (t *Splay[T]) Delete(value T) {
-	t.setRoot(delete(t.root(), value))
+	root, _ := delete(t.root(), value, t.cmp, t.counter)
+	t.setRoot(root)
 }
 
 func (t *Splay[T]) Contains(value T) bool {
-	return search(t.root(), value) != nil
+	found, last := lookup(t.root(), value, t.cmp)
+	t.touch(last)
+	return found != nil
 }
 
 func (t *Splay[T]) At(k uint) (T, error) {
 	result := at(t.root(), k)
 	if result == nil {
-		return T(rune(0)), bstrees.ErrIndexIsOutOfRange
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
 	}
+	t.touch(result)
 	return result.value, nil
 }
 
@@ -162,30 +264,33 @@ func (t *Splay[T]) Clear() {
 }
 
 func (t *Splay[T]) Index(value T) uint {
-	// return Rank(t.Root, value)
-	p := search(t.root(), value)
+	if t.noReadSplay {
+		return index(t.root(), value, t.cmp)
+	}
+	p := search(t.root(), value, t.cmp)
 	if p == nil {
-		prev := predecessor(t.root(), value)
+		prev := predecessor(t.root(), value, t.cmp)
 		if prev != nil {
-			splayRotate(prev, t.root())
+			splayRotate(prev, t.root(), t.counter)
 			if prev.left != nil {
 				return prev.left.size + prev.rec + 1
 			}
 			return prev.rec + 1
 		}
+		t.touchEnd(false)
 		return 1
 	}
-	splayRotate(p, t.root())
+	splayRotate(p, t.root(), t.counter)
 	if p.left != nil {
 		return p.left.size + 1
 	}
 	return 1
 }
 
-func predecessor[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T] {
+func predecessor[T any](root *splayNode[T], value T, cmp func(a, b T) int) *splayNode[T] {
 	var result *splayNode[T]
 	for p := root; p != nil; {
-		if value > p.value {
+		if cmp(value, p.value) > 0 {
 			result = p
 			p = p.right
 		} else {
@@ -196,17 +301,20 @@ func predecessor[T constraints.Ordered](root *splayNode[T], value T) *splayNode[
 }
 
 func (t *Splay[T]) Predecessor(value T) (T, error) {
-	prev := predecessor(t.root(), value)
+	prev := predecessor(t.root(), value, t.cmp)
 	if prev == nil {
-		return T(rune(0)), bstrees.ErrPredecessorDoesNotExist
+		t.touchEnd(false)
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
 	}
+	t.touch(prev)
 	return prev.value, nil
 }
 
-func successor[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T] {
+func successor[T any](root *splayNode[T], value T, cmp func(a, b T) int) *splayNode[T] {
 	var result *splayNode[T]
 	for p := root; p != nil; {
-		if value < p.value {
+		if cmp(value, p.value) < 0 {
 			result = p
 			p = p.left
 		} else {
@@ -217,9 +325,208 @@ func successor[T constraints.Ordered](root *splayNode[T], value T) *splayNode[T]
 }
 
 func (t *Splay[T]) Successor(value T) (T, error) {
-	next := successor(t.root(), value)
+	next := successor(t.root(), value, t.cmp)
 	if next == nil {
-		return T(rune(0)), bstrees.ErrSuccessorDoesNotExist
+		t.touchEnd(true)
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
+	t.touch(next)
 	return next.value, nil
 }
+
+func floor[T any](root *splayNode[T], value T, cmp func(a, b T) int) *splayNode[T] {
+	var result *splayNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c < 0 {
+			result = root
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return result
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *Splay[T]) Floor(value T) (T, bool) {
+	result := floor(t.root(), value, t.cmp)
+	if result == nil {
+		t.touchEnd(false)
+		var zero T
+		return zero, false
+	}
+	t.touch(result)
+	return result.value, true
+}
+
+func ceiling[T any](root *splayNode[T], value T, cmp func(a, b T) int) *splayNode[T] {
+	var result *splayNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c > 0 {
+			result = root
+			root = root.left
+		} else {
+			root = root.right
+		}
+	}
+	return result
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *Splay[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(t.root(), value, t.cmp)
+	if result == nil {
+		t.touchEnd(true)
+		var zero T
+		return zero, false
+	}
+	t.touch(result)
+	return result.value, true
+}
+
+func minimum[T any](root *splayNode[T]) *splayNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.left != nil {
+		root = root.left
+	}
+	return root
+}
+
+func maximum[T any](root *splayNode[T]) *splayNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.right != nil {
+		root = root.right
+	}
+	return root
+}
+
+// Min returns the smallest value.
+func (t *Splay[T]) Min() (T, bool) {
+	result := minimum(t.root())
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	t.touch(result)
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *Splay[T]) Max() (T, bool) {
+	result := maximum(t.root())
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	t.touch(result)
+	return result.value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *Splay[T]) PopMin() (T, bool) {
+	if t.root() == nil {
+		var zero T
+		return zero, false
+	}
+	p := minimum(t.root())
+	splayRotate(p, t.root(), t.counter)
+	if p.rec > 1 {
+		p.rec -= 1
+		p.size -= 1
+	} else {
+		t.setRoot(p.right)
+	}
+	return p.value, true
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *Splay[T]) PopMax() (T, bool) {
+	if t.root() == nil {
+		var zero T
+		return zero, false
+	}
+	p := maximum(t.root())
+	splayRotate(p, t.root(), t.counter)
+	if p.rec > 1 {
+		p.rec -= 1
+		p.size -= 1
+	} else {
+		t.setRoot(p.left)
+	}
+	return p.value, true
+}
+
+// Rank of the least value greater than or equal to value
+func index[T any](root *splayNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) < 0 {
+			rank += root.rec
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Rank of the least value greater than value
+func upperIndex[T any](root *splayNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) <= 0 {
+			rank += root.rec
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Count returns the number of copies of value.
+func (t *Splay[T]) Count(value T) uint {
+	found, last := lookup(t.root(), value, t.cmp)
+	t.touch(last)
+	if found != nil {
+		return found.rec
+	}
+	return 0
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *Splay[T]) EqualRange(value T) (first, last uint) {
+	first = index(t.root(), value, t.cmp)
+	return first, first + t.Count(value)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *Splay[T]) DeleteAll(value T) uint {
+	p := search(t.root(), value, t.cmp)
+	if p == nil {
+		return 0
+	}
+	splayRotate(p, t.root(), t.counter)
+	count := p.rec
+	p.rec = 1
+	p.update()
+	root, _ := delete(t.root(), value, t.cmp, t.counter)
+	t.setRoot(root)
+	return count
+}
@@ -0,0 +1,302 @@
+package splay_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+	"github.com/yanglinshu/bstrees/v2/splay"
+)
+
+func TestTree(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return splay.New[int]() })
+}
+
+func TestTreeFunc(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return splay.NewFunc(func(a, b int) int { return a - b })
+	})
+}
+
+func TestTreeNoReadSplay(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return splay.New[int](splay.NoReadSplay()) })
+}
+
+func TestMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return splay.NewMap[int, int]() })
+}
+
+func TestSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return splay.New[int](splay.Unique()) })
+}
+
+func TestFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return splay.FromSorted(values) })
+}
+
+func TestFromSortedUnique(t *testing.T) {
+	tree := splay.FromSorted([]int{1, 1, 2, 3, 3, 3}, splay.Unique())
+	if size := tree.Size(); size != 3 {
+		t.Errorf("Size() = %d, want 3", size)
+	}
+	if tree.Insert(2) {
+		t.Error("Insert(2) = true, want false")
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *splay.Splay[int] { return splay.New[int]() },
+		(*splay.Splay[int]).SplitAt, (*splay.Splay[int]).SplitRank, splay.Join[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *splay.Splay[int] { return splay.New[int]() },
+		splay.Union[int], splay.Intersection[int], splay.Difference[int])
+}
+
+func TestSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *splay.Splay[int] { return splay.New[int](splay.Unique()) },
+		splay.Union[int], splay.Intersection[int], splay.Difference[int])
+}
+
+func TestEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *splay.Splay[int] { return splay.New[int]() },
+		func() *splay.Splay[int] { return splay.New[int](splay.PreserveShape()) })
+}
+
+func TestString(t *testing.T) {
+	tree := splay.FromSorted([]int{1, 2, 2, 3})
+	want := `2 (rec=2)
+├── 1 (rec=1)
+└── 3 (rec=1)`
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := splay.New[int]()
+	data := `{"nodes":[{"value":1,"right":true,"count":1},{"value":2}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 2: holds no copy") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 2: holds no copy")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+// Value at the root of tree
+func root(tree *splay.Splay[int]) string {
+	return strings.SplitN(tree.String(), " ", 2)[0]
+}
+
+func TestReadSplay(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		read func(tree *splay.Splay[int])
+		want string
+	}{
+		{"Contains", func(tree *splay.Splay[int]) { tree.Contains(6) }, "6"},
+		{"ContainsAbsent", func(tree *splay.Splay[int]) { tree.Contains(8) }, "7"},
+		{"At", func(tree *splay.Splay[int]) { tree.At(1) }, "1"},
+		{"Index", func(tree *splay.Splay[int]) { tree.Index(5) }, "5"},
+		{"IndexAbsent", func(tree *splay.Splay[int]) { tree.Index(0) }, "1"},
+		{"Predecessor", func(tree *splay.Splay[int]) { tree.Predecessor(3) }, "2"},
+		{"PredecessorAbsent", func(tree *splay.Splay[int]) { tree.Predecessor(1) }, "1"},
+		{"Successor", func(tree *splay.Splay[int]) { tree.Successor(6) }, "7"},
+		{"Floor", func(tree *splay.Splay[int]) { tree.Floor(1) }, "1"},
+		{"Ceiling", func(tree *splay.Splay[int]) { tree.Ceiling(7) }, "7"},
+		{"Min", func(tree *splay.Splay[int]) { tree.Min() }, "1"},
+		{"Max", func(tree *splay.Splay[int]) { tree.Max() }, "7"},
+		{"Count", func(tree *splay.Splay[int]) { tree.Count(3) }, "3"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tree := splay.FromSorted([]int{1, 2, 3, 4, 5, 6, 7})
+			c.read(tree)
+			if got := root(tree); got != c.want {
+				t.Errorf("root = %s, want %s", got, c.want)
+			}
+			if err := tree.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+
+			tree = splay.FromSorted([]int{1, 2, 3, 4, 5, 6, 7}, splay.NoReadSplay())
+			want := tree.String()
+			c.read(tree)
+			if got := tree.String(); got != want {
+				t.Errorf("String() = \n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSplay(t *testing.T) {
+	tree := splay.FromSorted([]int{1, 2, 3, 4, 5, 6, 7}, splay.NoReadSplay())
+	if tree.SplaysReads() {
+		t.Error("SplaysReads() = true, want false")
+	}
+	if !tree.Splay(6) {
+		t.Error("Splay(6) = false, want true")
+	}
+	if got := root(tree); got != "6" {
+		t.Errorf("root = %s, want 6", got)
+	}
+	if tree.Splay(0) {
+		t.Error("Splay(0) = true, want false")
+	}
+	if got := root(tree); got != "1" {
+		t.Errorf("root = %s, want 1", got)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestTopDown(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return splay.NewTopDown[int]() })
+}
+
+func TestTopDownNoReadSplay(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return splay.NewTopDown[int](splay.NoReadSplay()) })
+}
+
+func TestTopDownMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return splay.NewTopDownMap[int, int]() })
+}
+
+func TestTopDownSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return splay.NewTopDown[int](splay.Unique()) })
+}
+
+func TestTopDownFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return splay.TopDownFromSorted(values) })
+}
+
+func TestTopDownSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *splay.TopDown[int] { return splay.NewTopDown[int]() },
+		(*splay.TopDown[int]).SplitAt, (*splay.TopDown[int]).SplitRank, splay.JoinTopDown[int])
+}
+
+func TestTopDownSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *splay.TopDown[int] { return splay.NewTopDown[int]() },
+		splay.UnionTopDown[int], splay.IntersectionTopDown[int], splay.DifferenceTopDown[int])
+}
+
+func TestTopDownSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *splay.TopDown[int] { return splay.NewTopDown[int](splay.Unique()) },
+		splay.UnionTopDown[int], splay.IntersectionTopDown[int], splay.DifferenceTopDown[int])
+}
+
+func TestTopDownEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *splay.TopDown[int] { return splay.NewTopDown[int]() },
+		func() *splay.TopDown[int] { return splay.NewTopDown[int](splay.PreserveShape()) })
+
+	// Both variants share their encoding
+	data, err := json.Marshal(splay.FromSorted([]int{1, 2, 2, 3}, splay.PreserveShape()))
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	tree := splay.NewTopDown[int]()
+	if err := json.Unmarshal(data, tree); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := `2 (rec=2)
+├── 1 (rec=1)
+└── 3 (rec=1)`
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTopDownSplay(t *testing.T) {
+	tree := splay.TopDownFromSorted([]int{1, 2, 3, 4, 5, 6, 7})
+	tree.Contains(7)
+	want := `7 (rec=1)
+├── 6 (rec=1)
+│   ├── 4 (rec=1)
+│   │   ├── 2 (rec=1)
+│   │   │   ├── 1 (rec=1)
+│   │   │   └── 3 (rec=1)
+│   │   └── 5 (rec=1)
+│   └── <nil>
+└── <nil>`
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+	if got, err := tree.At(1); err != nil || got != 1 {
+		t.Errorf("At(1) = %d, %v, want 1, nil", got, err)
+	}
+	if got := strings.SplitN(tree.String(), " ", 2)[0]; got != "1" {
+		t.Errorf("root = %s, want 1", got)
+	}
+
+	tree = splay.TopDownFromSorted([]int{1, 2, 3, 4, 5, 6, 7}, splay.NoReadSplay())
+	want = tree.String()
+	tree.Contains(7)
+	tree.Predecessor(3)
+	if got := tree.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+	if !tree.Splay(6) || tree.Splay(0) {
+		t.Error("Splay(6), Splay(0) = false, true, want true, false")
+	}
+	if got := strings.SplitN(tree.String(), " ", 2)[0]; got != "1" {
+		t.Errorf("root = %s, want 1", got)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestTopDownValidate(t *testing.T) {
+	tree := splay.NewTopDown[int]()
+	data := `{"nodes":[{"value":2,"left":true,"count":1},{"value":2,"count":1}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 2: is equal to the value before it in a set") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 2: is equal to the value before it in a set")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+// Insert random values, then read them with a skewed distribution
+func benchmarkTree(b *testing.B, tree bstrees.Tree[int]) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		tree.Insert(r.Intn(100000))
+	}
+	zipf := rand.NewZipf(r, 1.1, 1, 99999)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		value := int(zipf.Uint64())
+		if i%4 == 0 {
+			tree.Insert(value)
+			tree.Delete(value)
+		} else {
+			tree.Index(value)
+			tree.Contains(value)
+		}
+	}
+}
+
+func BenchmarkSplay(b *testing.B) {
+	benchmarkTree(b, splay.New[int]())
+}
+
+func BenchmarkTopDown(b *testing.B) {
+	benchmarkTree(b, splay.NewTopDown[int]())
+}
+
+func BenchmarkRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return splay.New[int]() })
+}
+
+func BenchmarkTopDownRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return splay.NewTopDown[int]() })
+}
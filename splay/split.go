@@ -0,0 +1,187 @@
+package splay
+
+// Create a tree with the order and options of t holding root
+func (t *Splay[T]) with(root *splayNode[T]) *Splay[T] {
+	var zero T
+	result := &Splay[T]{superRoot: newSplayNode(zero), cmp: t.cmp, options: t.options, counter: t.counter}
+	result.setRoot(root)
+	return result
+}
+
+// Detach the left subtree of the root of t, returns it as a tree of its own
+func (t *Splay[T]) cutLeft() *splayNode[T] {
+	root := t.root()
+	left := root.left
+	root.left = nil
+	root.update()
+	if left != nil {
+		left.parent = nil
+	}
+	return left
+}
+
+// SplitAt moves the values less than value to left and the others to right
+// in amortized O(log n). t is left empty.
+func (t *Splay[T]) SplitAt(value T) (left, right *Splay[T]) {
+	p := ceiling(t.root(), value, t.cmp)
+	if p == nil {
+		left, right = t.with(t.root()), t.with(nil)
+	} else {
+		splayRotate(p, t.root(), t.counter)
+		left, right = t.with(t.cutLeft()), t.with(p)
+	}
+	t.setRoot(nil)
+	return left, right
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// amortized O(log n). t is left empty.
+func (t *Splay[T]) SplitRank(k uint) (left, right *Splay[T]) {
+	if k >= t.Size() {
+		left, right = t.with(t.root()), t.with(nil)
+	} else {
+		p := at(t.root(), k+1)
+		splayRotate(p, t.root(), t.counter)
+		leftSize := t.Size() - p.rec
+		if p.right != nil {
+			leftSize -= p.right.size
+		}
+		leftRoot := t.cutLeft()
+		if k > leftSize {
+			// p holds both the k-th and the (k+1)-th values, so its copies are
+			// shared between a new node on the left and p on the right
+			q := newSplayNode(p.value)
+			q.rec = k - leftSize
+			q.setChild(leftRoot, false)
+			q.update()
+			p.rec -= q.rec
+			p.update()
+			leftRoot = q
+		}
+		left, right = t.with(leftRoot), t.with(p)
+	}
+	t.setRoot(nil)
+	return left, right
+}
+
+// Join moves the values of left and right to a new tree in amortized
+// O(log n). Every value of left must be less than every value of right, or
+// equal to it if the trees are not sets, which is not checked. The new tree
+// is ordered like left, and left and right are left empty.
+func Join[T any](left, right *Splay[T]) *Splay[T] {
+	result := left.with(nil)
+	if left.root() == nil {
+		result.setRoot(right.root())
+	} else {
+		p := maximum(left.root())
+		splayRotate(p, left.root(), left.counter)
+		if q := minimum(right.root()); q != nil {
+			splayRotate(q, right.root(), left.counter)
+			if left.cmp(p.value, q.value) == 0 {
+				// Equal values are counted by a single node
+				p.rec += q.rec
+				p.setChild(q.right, true)
+			} else {
+				p.setChild(q, true)
+			}
+			p.update()
+		}
+		result.setRoot(p)
+	}
+	left.setRoot(nil)
+	right.setRoot(nil)
+	return result
+}
+
+// Create a tree with the order and options of t holding root
+func (t *TopDown[T]) with(root *topDownNode[T]) *TopDown[T] {
+	return &TopDown[T]{root: root, cmp: t.cmp, options: t.options, counter: t.counter}
+}
+
+// SplitAt moves the values less than value to left and the others to right
+// in amortized O(log n). t is left empty.
+func (t *TopDown[T]) SplitAt(value T) (left, right *TopDown[T]) {
+	root := splayTopDown(t.root, func(n *topDownNode[T]) int {
+		if t.cmp(n.value, value) < 0 {
+			return 1
+		}
+		return -1
+	}, t.counter)
+	t.root = nil
+	if root == nil {
+		return t.with(nil), t.with(nil)
+	}
+	if t.cmp(root.value, value) < 0 {
+		// root holds the greatest value less than value
+		rightRoot := root.right
+		root.right = nil
+		root.update()
+		return t.with(root), t.with(rightRoot)
+	}
+	leftRoot := root.left
+	root.left = nil
+	root.update()
+	return t.with(leftRoot), t.with(root)
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// amortized O(log n). t is left empty.
+func (t *TopDown[T]) SplitRank(k uint) (left, right *TopDown[T]) {
+	if k >= t.Size() {
+		left, right = t.with(t.root), t.with(nil)
+		t.root = nil
+		return left, right
+	}
+	// Splay the node of the (k+1)-th value
+	rest := k + 1
+	root := splayTopDown(t.root, func(n *topDownNode[T]) int {
+		leftSize := sizeOf(n.left)
+		if rest <= leftSize {
+			return -1
+		} else if rest <= leftSize+n.rec {
+			return 0
+		}
+		rest -= leftSize + n.rec
+		return 1
+	}, t.counter)
+	t.root = nil
+	leftRoot := root.left
+	root.left = nil
+	if leftSize := sizeOf(leftRoot); k > leftSize {
+		// root holds both the k-th and the (k+1)-th values, so its copies
+		// are shared between a new node on the left and root on the right
+		q := newTopDownNode(root.value)
+		q.rec = k - leftSize
+		q.left = leftRoot
+		q.update()
+		root.rec -= q.rec
+		leftRoot = q
+	}
+	root.update()
+	return t.with(leftRoot), t.with(root)
+}
+
+// JoinTopDown moves the values of left and right to a new tree in amortized
+// O(log n), see Join. left and right are left empty.
+func JoinTopDown[T any](left, right *TopDown[T]) *TopDown[T] {
+	result := left.with(nil)
+	if left.root == nil {
+		result.root = right.root
+	} else {
+		p := splayTopDown(left.root, toEnd[T](true), left.counter)
+		if q := splayTopDown(right.root, toEnd[T](false), left.counter); q != nil {
+			if left.cmp(p.value, q.value) == 0 {
+				// Equal values are counted by a single node
+				p.rec += q.rec
+				p.right = q.right
+			} else {
+				p.right = q
+			}
+			p.update()
+		}
+		result.root = p
+	}
+	left.root = nil
+	right.root = nil
+	return result
+}
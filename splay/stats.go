@@ -0,0 +1,24 @@
+package splay
+
+import "github.com/yanglinshu/bstrees/v2"
+
+// Stats returns the operations performed on t since it has been created or
+// since the last ResetStats. The trees made from t, such as the trees it is
+// split into, add to the same counts. Operations are only counted when the
+// module is built with the bstrees_stats tag, and cost nothing otherwise.
+func (t *Splay[T]) Stats() bstrees.Stats {
+	return t.counter.Stats()
+}
+
+func (t *Splay[T]) ResetStats() {
+	t.counter.Reset()
+}
+
+// Stats returns the operations performed on t, see Splay.Stats.
+func (t *TopDown[T]) Stats() bstrees.Stats {
+	return t.counter.Stats()
+}
+
+func (t *TopDown[T]) ResetStats() {
+	t.counter.Reset()
+}
@@ -0,0 +1,56 @@
+//go:build bstrees_stats
+
+package splay_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/splay"
+)
+
+func TestStats(t *testing.T) {
+	tree := splay.New[int]()
+	other := splay.New[int]()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		tree.Insert(r.Intn(500))
+		tree.Delete(r.Intn(500))
+	}
+	if stats := other.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() of another tree = %+v, want zero", stats)
+	}
+	stats := tree.Stats()
+	for _, c := range []struct {
+		name  string
+		count uint64
+	}{
+		{"Comparisons", stats.Comparisons},
+		{"Rotations", stats.Rotations},
+		{"Zigs", stats.Zigs},
+		{"ZigZigs", stats.ZigZigs},
+		{"ZigZags", stats.ZigZags},
+	} {
+		if c.count == 0 {
+			t.Errorf("Stats().%s = 0, want > 0", c.name)
+		}
+	}
+	tree.ResetStats()
+	if stats := tree.Stats(); stats != (bstrees.Stats{}) {
+		t.Errorf("Stats() = %+v after ResetStats, want zero", stats)
+	}
+}
+
+func TestTopDownStats(t *testing.T) {
+	tree := splay.NewTopDown[int]()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		tree.Insert(r.Intn(500))
+		tree.Delete(r.Intn(500))
+	}
+	stats := tree.Stats()
+	if stats.Comparisons == 0 || stats.Rotations == 0 || stats.Zigs == 0 || stats.ZigZigs == 0 {
+		t.Errorf("Stats() = %+v, want comparisons, rotations, zigs and zig-zigs", stats)
+	}
+}
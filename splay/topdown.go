@@ -0,0 +1,483 @@
+package splay
+
+import (
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
+	"golang.org/x/exp/constraints"
+)
+
+var _ bstrees.Tree[int] = (*TopDown[int])(nil)
+
+// TopDown is a splay tree splaying top-down, as described by Sleator and
+// Tarjan: a node is splayed on the way down to it, so that the nodes need no
+// parent link. It has the methods of Splay, except that a read splays the
+// last node of its search path, which is not always the node it returns.
+// TopDown must be created by a constructor such as NewTopDown or
+// NewTopDownFunc.
+type TopDown[T any] struct {
+	root *topDownNode[T]
+	cmp  func(a, b T) int
+	options
+	counter *stats.Counter // Shared with the trees made from this one
+}
+
+func NewTopDown[T constraints.Ordered](opts ...Option) *TopDown[T] {
+	return NewTopDownFunc(bstrees.Compare[T], opts...)
+}
+
+// NewTopDownFunc creates a tree ordered by cmp, see NewFunc.
+func NewTopDownFunc[T any](cmp func(a, b T) int, opts ...Option) *TopDown[T] {
+	counter := new(stats.Counter)
+	return &TopDown[T]{
+		root:    nil,
+		cmp:     stats.Compare(counter, cmp),
+		options: newOptions(opts),
+		counter: counter,
+	}
+}
+
+// TopDownFromSorted builds a tree from values sorted in ascending order in
+// O(n). The order of values is not checked.
+func TopDownFromSorted[T constraints.Ordered](values []T, opts ...Option) *TopDown[T] {
+	return TopDownFromSortedFunc(bstrees.Compare[T], values, opts...)
+}
+
+// TopDownFromSortedFunc builds a tree ordered by cmp from values sorted by cmp
+// in O(n), see NewTopDownFunc and TopDownFromSorted.
+func TopDownFromSortedFunc[T any](cmp func(a, b T) int, values []T, opts ...Option) *TopDown[T] {
+	t := NewTopDownFunc(cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
+	}
+	t.root = topDownFromSorted(values, cmp)
+	return t
+}
+
+// Build a balanced tree from sorted values, see fromSorted
+func topDownFromSorted[T any](values []T, cmp func(a, b T) int) *topDownNode[T] {
+	if len(values) == 0 {
+		return nil
+	}
+	mid := len(values) / 2
+	first, last := mid, mid+1
+	for first > 0 && cmp(values[first-1], values[mid]) == 0 {
+		first--
+	}
+	for last < len(values) && cmp(values[last], values[mid]) == 0 {
+		last++
+	}
+	root := newTopDownNode(values[mid])
+	root.rec = uint(last - first)
+	root.left = topDownFromSorted(values[:first], cmp)
+	root.right = topDownFromSorted(values[last:], cmp)
+	root.update()
+	return root
+}
+
+// A search in a TopDown tree. It is called once on each node of the search
+// path, from the root down, before the node is moved, and returns a negative
+// number to go left, a positive number to go right, or zero to stop.
+type direction[T any] func(n *topDownNode[T]) int
+
+// Follow dir from root without modifying the tree, returns the last node of
+// the search path
+func walk[T any](root *topDownNode[T], dir direction[T]) *topDownNode[T] {
+	for root != nil {
+		c := dir(root)
+		next := root.left
+		if c > 0 {
+			next = root.right
+		}
+		if c == 0 || next == nil {
+			return root
+		}
+		root = next
+	}
+	return nil
+}
+
+// Follow dir from root, splaying the last node of the search path to the
+// root on the way down. The nodes passed are linked to a left tree and a
+// right tree, whose sizes are only known at the end and fixed then.
+// Returns the new root.
+func splayTopDown[T any](root *topDownNode[T], dir direction[T], counter *stats.Counter) *topDownNode[T] {
+	if root == nil {
+		return nil
+	}
+	var header topDownNode[T]
+	left, right := &header, &header // Greatest node of the left tree, least of the right tree
+	leftSize, rightSize := uint(0), uint(0)
+	for c := dir(root); c != 0; {
+		if c < 0 {
+			if root.left == nil {
+				break
+			}
+			if next := dir(root.left); next < 0 {
+				// zig-zig: rotate right, then link the new root
+				counter.ZigZig()
+				counter.Rotation()
+				child := root.left
+				root.left = child.right
+				child.right = root
+				root.update()
+				root = child
+				if root.left == nil {
+					break
+				}
+				right.left = root
+				right = root
+				rightSize += root.rec + sizeOf(root.right)
+				root = root.left
+				c = dir(root)
+			} else {
+				counter.Zig()
+				right.left = root
+				right = root
+				rightSize += root.rec + sizeOf(root.right)
+				root = root.left
+				c = next
+			}
+		} else {
+			if root.right == nil {
+				break
+			}
+			if next := dir(root.right); next > 0 {
+				// zig-zig: rotate left, then link the new root
+				counter.ZigZig()
+				counter.Rotation()
+				child := root.right
+				root.right = child.left
+				child.left = root
+				root.update()
+				root = child
+				if root.right == nil {
+					break
+				}
+				left.right = root
+				left = root
+				leftSize += root.rec + sizeOf(root.left)
+				root = root.right
+				c = dir(root)
+			} else {
+				counter.Zig()
+				left.right = root
+				left = root
+				leftSize += root.rec + sizeOf(root.left)
+				root = root.right
+				c = next
+			}
+		}
+	}
+	leftSize += sizeOf(root.left)
+	rightSize += sizeOf(root.right)
+	root.size = leftSize + rightSize + root.rec
+	left.right, right.left = nil, nil
+	for p := header.right; p != nil; p = p.right {
+		p.size = leftSize
+		leftSize -= p.rec + sizeOf(p.left)
+	}
+	for p := header.left; p != nil; p = p.left {
+		p.size = rightSize
+		rightSize -= p.rec + sizeOf(p.right)
+	}
+	left.right, right.left = root.left, root.right
+	root.left, root.right = header.right, header.left
+	return root
+}
+
+// Search along dir, splaying the last node of the search path unless reads do
+// not splay. Returns that node.
+func (t *TopDown[T]) search(dir direction[T]) *topDownNode[T] {
+	if t.noReadSplay {
+		return walk(t.root, dir)
+	}
+	t.root = splayTopDown(t.root, dir, t.counter)
+	return t.root
+}
+
+// Direction toward value
+func (t *TopDown[T]) toward(value T) direction[T] {
+	return func(n *topDownNode[T]) int {
+		return t.cmp(value, n.value)
+	}
+}
+
+// Splay moves the node holding value to the root, or the last node visited
+// looking for it if value is absent, and reports whether value is present.
+// It splays even if the tree has been created with NoReadSplay.
+func (t *TopDown[T]) Splay(value T) bool {
+	t.root = splayTopDown(t.root, t.toward(value), t.counter)
+	return t.root != nil && t.cmp(value, t.root.value) == 0
+}
+
+// SplaysReads reports whether the reads of t splay the last node of their
+// search path, that is whether t has been created without NoReadSplay.
+func (t *TopDown[T]) SplaysReads() bool {
+	return !t.noReadSplay
+}
+
+func (t *TopDown[T]) Insert(value T) bool {
+	if t.root == nil {
+		t.root = newTopDownNode(value)
+		return true
+	}
+	t.root = splayTopDown(t.root, t.toward(value), t.counter)
+	root := t.root
+	c := t.cmp(value, root.value)
+	if c == 0 {
+		if t.unique {
+			return false
+		}
+		root.rec += 1
+		root.size += 1
+		return true
+	}
+	node := newTopDownNode(value)
+	if c < 0 {
+		node.left, node.right = root.left, root
+		root.left = nil
+	} else {
+		node.left, node.right = root, root.right
+		root.right = nil
+	}
+	root.update()
+	node.update()
+	t.root = node
+	return true
+}
+
+// Remove the root of t, whatever the number of copies it holds
+func (t *TopDown[T]) removeRoot() {
+	root := t.root
+	if root.left == nil {
+		t.root = root.right
+		return
+	}
+	// The greatest node of the left subtree has no right child once splayed
+	left := splayTopDown(root.left, func(n *topDownNode[T]) int { return 1 }, t.counter)
+	left.right = root.right
+	left.update()
+	t.root = left
+}
+
+func (t *TopDown[T]) Delete(value T) {
+	t.delete(value)
+}
+
+// Same as Delete, but also returns whether value has been deleted
+func (t *TopDown[T]) delete(value T) bool {
+	t.root = splayTopDown(t.root, t.toward(value), t.counter)
+	if t.root == nil || t.cmp(value, t.root.value) != 0 {
+		return false
+	}
+	if t.root.rec > 1 {
+		t.root.rec -= 1
+		t.root.size -= 1
+	} else {
+		t.removeRoot()
+	}
+	return true
+}
+
+func (t *TopDown[T]) Contains(value T) bool {
+	p := t.search(t.toward(value))
+	return p != nil && t.cmp(value, p.value) == 0
+}
+
+func (t *TopDown[T]) At(k uint) (T, error) {
+	var result *topDownNode[T]
+	t.search(func(n *topDownNode[T]) int {
+		leftSize := sizeOf(n.left)
+		if k <= leftSize {
+			return -1
+		} else if k <= leftSize+n.rec {
+			result = n
+			return 0
+		}
+		k -= leftSize + n.rec
+		return 1
+	})
+	if result == nil {
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
+	}
+	return result.value, nil
+}
+
+func (t *TopDown[T]) Size() uint {
+	return sizeOf(t.root)
+}
+
+func (t *TopDown[T]) Empty() bool {
+	return t.root == nil
+}
+
+func (t *TopDown[T]) Clear() {
+	t.root = nil
+}
+
+// Rank of the least value greater than or equal to value, or greater than
+// value if upper is set
+func (t *TopDown[T]) rank(value T, upper bool) uint {
+	rank := uint(0)
+	t.search(func(n *topDownNode[T]) int {
+		if c := t.cmp(n.value, value); c < 0 || (c == 0 && upper) {
+			rank += n.rec + sizeOf(n.left)
+			return 1
+		}
+		return -1
+	})
+	return rank + 1
+}
+
+func (t *TopDown[T]) Index(value T) uint {
+	return t.rank(value, false)
+}
+
+func (t *TopDown[T]) Predecessor(value T) (T, error) {
+	var result *topDownNode[T]
+	t.search(func(n *topDownNode[T]) int {
+		if t.cmp(value, n.value) > 0 {
+			result = n
+			return 1
+		}
+		return -1
+	})
+	if result == nil {
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
+	}
+	return result.value, nil
+}
+
+func (t *TopDown[T]) Successor(value T) (T, error) {
+	var result *topDownNode[T]
+	t.search(func(n *topDownNode[T]) int {
+		if t.cmp(value, n.value) < 0 {
+			result = n
+			return -1
+		}
+		return 1
+	})
+	if result == nil {
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
+	}
+	return result.value, nil
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *TopDown[T]) Floor(value T) (T, bool) {
+	var result *topDownNode[T]
+	t.search(func(n *topDownNode[T]) int {
+		c := t.cmp(value, n.value)
+		if c >= 0 {
+			result = n
+		}
+		return c
+	})
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *TopDown[T]) Ceiling(value T) (T, bool) {
+	var result *topDownNode[T]
+	t.search(func(n *topDownNode[T]) int {
+		c := t.cmp(value, n.value)
+		if c <= 0 {
+			result = n
+		}
+		return c
+	})
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Direction toward the least node, or the greatest one if greatest is set
+func toEnd[T any](greatest bool) direction[T] {
+	if greatest {
+		return func(n *topDownNode[T]) int { return 1 }
+	}
+	return func(n *topDownNode[T]) int { return -1 }
+}
+
+// Min returns the smallest value.
+func (t *TopDown[T]) Min() (T, bool) {
+	result := t.search(toEnd[T](false))
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *TopDown[T]) Max() (T, bool) {
+	result := t.search(toEnd[T](true))
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Remove one copy of the least value, or of the greatest one if greatest is
+// set
+func (t *TopDown[T]) pop(greatest bool) (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	t.root = splayTopDown(t.root, toEnd[T](greatest), t.counter)
+	value := t.root.value
+	if t.root.rec > 1 {
+		t.root.rec -= 1
+		t.root.size -= 1
+	} else {
+		t.removeRoot()
+	}
+	return value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *TopDown[T]) PopMin() (T, bool) {
+	return t.pop(false)
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *TopDown[T]) PopMax() (T, bool) {
+	return t.pop(true)
+}
+
+// Count returns the number of copies of value.
+func (t *TopDown[T]) Count(value T) uint {
+	if p := t.search(t.toward(value)); p != nil && t.cmp(value, p.value) == 0 {
+		return p.rec
+	}
+	return 0
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *TopDown[T]) EqualRange(value T) (first, last uint) {
+	return t.rank(value, false), t.rank(value, true)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *TopDown[T]) DeleteAll(value T) uint {
+	t.root = splayTopDown(t.root, t.toward(value), t.counter)
+	if t.root == nil || t.cmp(value, t.root.value) != 0 {
+		return 0
+	}
+	count := t.root.rec
+	t.removeRoot()
+	return count
+}
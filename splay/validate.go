@@ -0,0 +1,78 @@
+package splay
+
+import "github.com/yanglinshu/bstrees/v2/internal/invariant"
+
+func validate[T any](root *splayNode[T], order *invariant.Order[T]) error {
+	if root == nil {
+		return nil
+	}
+	for _, child := range []*splayNode[T]{root.left, root.right} {
+		if child != nil && child.parent != root {
+			return invariant.Errorf(child.value, "parent link does not point to %v", root.value)
+		}
+	}
+	if err := validate(root.left, order); err != nil {
+		return err
+	}
+	if root.rec == 0 {
+		return invariant.Errorf(root.value, "holds no copy")
+	}
+	if err := order.Next(root.value); err != nil {
+		return err
+	}
+	if err := validate(root.right, order); err != nil {
+		return err
+	}
+	want := root.rec
+	if root.left != nil {
+		want += root.left.size
+	}
+	if root.right != nil {
+		want += root.right.size
+	}
+	if root.size != want {
+		return invariant.Errorf(root.value, "size is %d, want %d", root.size, want)
+	}
+	return nil
+}
+
+// Validate checks the order of the values, which are all distinct as the
+// copies of a value share a node, and for every node its parent link, its
+// number of copies and its cached size. Returns a *bstrees.InvariantError
+// naming the first node found to break a rule.
+func (t *Splay[T]) Validate() error {
+	if root := t.root(); root != nil && root.parent != t.superRoot {
+		return invariant.Errorf(root.value, "root is not linked to the tree")
+	}
+	return validate(t.root(), invariant.NewOrder(t.cmp, true))
+}
+
+func (n *topDownNode[T]) validate(order *invariant.Order[T]) error {
+	if n == nil {
+		return nil
+	}
+	if err := n.left.validate(order); err != nil {
+		return err
+	}
+	if n.rec == 0 {
+		return invariant.Errorf(n.value, "holds no copy")
+	}
+	if err := order.Next(n.value); err != nil {
+		return err
+	}
+	if err := n.right.validate(order); err != nil {
+		return err
+	}
+	if want := n.rec + sizeOf(n.left) + sizeOf(n.right); n.size != want {
+		return invariant.Errorf(n.value, "size is %d, want %d", n.size, want)
+	}
+	return nil
+}
+
+// Validate checks the order of the values, which are all distinct as the
+// copies of a value share a node, and for every node its number of copies and
+// its cached size. Returns a *bstrees.InvariantError naming the first node
+// found to break a rule.
+func (t *TopDown[T]) Validate() error {
+	return t.root.validate(invariant.NewOrder(t.cmp, true))
+}
@@ -0,0 +1,20 @@
+package bstrees
+
+// Stats counts the work done by a tree, as returned by its Stats method. It
+// is only filled when the module is built with the bstrees_stats tag, and
+// each tree only counts what it performs.
+type Stats struct {
+	Comparisons     uint64  // Calls to the comparison function
+	Rotations       uint64  // Single rotations, a double rotation counting as two
+	DoubleRotations uint64  // Red-black tree double rotations
+	Zigs            uint64  // Splay steps rotating a node under its target
+	ZigZigs         uint64  // Splay steps rotating the parent, then the node
+	ZigZags         uint64  // Splay steps rotating the node twice
+	Skews           uint64  // Anderson tree skews which have rotated
+	LevelSplits     uint64  // Anderson tree splits which have rotated, raising a level
+	Splits          uint64  // Treap split calls, recursive ones included
+	Merges          uint64  // Treap merge calls, recursive ones included
+	Rebuilds        uint64  // Scapegoat subtrees rebuilt
+	RebuiltNodes    uint64  // Nodes of the rebuilt scapegoat subtrees
+	TombstoneRatio  float64 // Share of the scapegoat nodes holding a deleted value, when Stats is called
+}
@@ -0,0 +1,218 @@
+// Package syncbst makes the trees of bstrees safe for concurrent use.
+package syncbst
+
+import (
+	"sync"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/anderson"
+	"github.com/yanglinshu/bstrees/v2/avl"
+	"github.com/yanglinshu/bstrees/v2/fhq"
+	"github.com/yanglinshu/bstrees/v2/rb"
+	"github.com/yanglinshu/bstrees/v2/scapegoat"
+	"github.com/yanglinshu/bstrees/v2/splay"
+	"github.com/yanglinshu/bstrees/v2/treap"
+)
+
+var _ bstrees.Tree[int] = (*Tree[int])(nil)
+
+// A set of the read methods of Tree
+type methods uint16
+
+const (
+	contains methods = 1 << iota
+	size             // Size and Empty
+	at
+	index
+	predecessor
+	successor
+	floor
+	ceiling
+	extremes // Min and Max
+	allReads = contains | size | at | index | predecessor | successor | floor | ceiling | extremes
+)
+
+// Queries of the trees of this module beyond bstrees.Tree
+type bounded[T any] interface {
+	Floor(value T) (T, bool)
+	Ceiling(value T) (T, bool)
+	Min() (T, bool)
+	Max() (T, bool)
+}
+
+// Read methods of tree that leave it unmodified, and can run concurrently
+func readOnly[T any](tree bstrees.Tree[T]) methods {
+	switch tree := tree.(type) {
+	case *avl.AVLTree[T], *rb.RBTree[T], *anderson.AndersonTree[T], *treap.Treap[T], *fhq.FHQTreap[T], *scapegoat.ScapeGoatTree[T]:
+		return allReads
+	case *splay.Splay[T]:
+		if tree.SplaysReads() {
+			return size
+		}
+		return allReads
+	case *splay.TopDown[T]:
+		if tree.SplaysReads() {
+			return size
+		}
+		return allReads
+	}
+	return 0
+}
+
+// Tree wraps a tree with a sync.RWMutex. Read methods share the lock when
+// the wrapped tree is known to leave it unmodified, and take it exclusively
+// otherwise, so that any bstrees.Tree can be wrapped.
+type Tree[T any] struct {
+	mu       sync.RWMutex
+	tree     bstrees.Tree[T]
+	readOnly methods
+	set      bool // Whether tree keeps at most one copy of each value
+}
+
+// New wraps tree, which must not be used directly afterwards.
+func New[T any](tree bstrees.Tree[T]) *Tree[T] {
+	set, ok := tree.(interface{ IsSet() bool })
+	return &Tree[T]{tree: tree, readOnly: readOnly(tree), set: ok && set.IsSet()}
+}
+
+// Locks t for calling method m, returns the function unlocking it
+func (t *Tree[T]) lock(m methods) func() {
+	if t.readOnly&m != 0 {
+		t.mu.RLock()
+		return t.mu.RUnlock
+	}
+	t.mu.Lock()
+	return t.mu.Unlock
+}
+
+func (t *Tree[T]) Insert(value T) bool {
+	defer t.lock(0)()
+	return t.tree.Insert(value)
+}
+
+func (t *Tree[T]) Delete(value T) {
+	defer t.lock(0)()
+	t.tree.Delete(value)
+}
+
+func (t *Tree[T]) Contains(value T) bool {
+	defer t.lock(contains)()
+	return t.tree.Contains(value)
+}
+
+func (t *Tree[T]) Size() uint {
+	defer t.lock(size)()
+	return t.tree.Size()
+}
+
+func (t *Tree[T]) Empty() bool {
+	defer t.lock(size)()
+	return t.tree.Empty()
+}
+
+func (t *Tree[T]) Clear() {
+	defer t.lock(0)()
+	t.tree.Clear()
+}
+
+func (t *Tree[T]) At(k uint) (T, error) {
+	defer t.lock(at)()
+	return t.tree.At(k)
+}
+
+func (t *Tree[T]) Index(value T) uint {
+	defer t.lock(index)()
+	return t.tree.Index(value)
+}
+
+func (t *Tree[T]) Predecessor(value T) (T, error) {
+	defer t.lock(predecessor)()
+	return t.tree.Predecessor(value)
+}
+
+func (t *Tree[T]) Successor(value T) (T, error) {
+	defer t.lock(successor)()
+	return t.tree.Successor(value)
+}
+
+// Floor returns the greatest value less than or equal to value. It answers
+// from Contains and Predecessor if the wrapped tree has no Floor.
+func (t *Tree[T]) Floor(value T) (T, bool) {
+	defer t.lock(floor)()
+	if tree, ok := t.tree.(bounded[T]); ok {
+		return tree.Floor(value)
+	}
+	if t.tree.Contains(value) {
+		return value, true
+	}
+	result, err := t.tree.Predecessor(value)
+	return result, err == nil
+}
+
+// Ceiling returns the least value greater than or equal to value. It answers
+// from Contains and Successor if the wrapped tree has no Ceiling.
+func (t *Tree[T]) Ceiling(value T) (T, bool) {
+	defer t.lock(ceiling)()
+	if tree, ok := t.tree.(bounded[T]); ok {
+		return tree.Ceiling(value)
+	}
+	if t.tree.Contains(value) {
+		return value, true
+	}
+	result, err := t.tree.Successor(value)
+	return result, err == nil
+}
+
+// Min returns the least value of the tree.
+func (t *Tree[T]) Min() (T, bool) {
+	defer t.lock(extremes)()
+	if tree, ok := t.tree.(bounded[T]); ok {
+		return tree.Min()
+	}
+	result, err := t.tree.At(1)
+	return result, err == nil
+}
+
+// Max returns the greatest value of the tree.
+func (t *Tree[T]) Max() (T, bool) {
+	defer t.lock(extremes)()
+	if tree, ok := t.tree.(bounded[T]); ok {
+		return tree.Max()
+	}
+	result, err := t.tree.At(t.tree.Size())
+	return result, err == nil
+}
+
+// InsertIfAbsent inserts value unless it is already present, and returns
+// whether it has been inserted.
+func (t *Tree[T]) InsertIfAbsent(value T) bool {
+	defer t.lock(0)()
+	if !t.set && t.tree.Contains(value) {
+		return false
+	}
+	return t.tree.Insert(value)
+}
+
+// Replace deletes one copy of old and inserts value in its place, and returns
+// false without modifying the tree if old is absent, or if the tree is a set
+// already holding value.
+func (t *Tree[T]) Replace(old, value T) bool {
+	defer t.lock(0)()
+	if !t.tree.Contains(old) {
+		return false
+	}
+	t.tree.Delete(old)
+	if !t.tree.Insert(value) {
+		t.tree.Insert(old)
+		return false
+	}
+	return true
+}
+
+// Do calls fn with the wrapped tree while holding the lock exclusively, so
+// that fn can run any sequence of operations atomically. fn must not keep
+// the tree.
+func (t *Tree[T]) Do(fn func(tree bstrees.Tree[T])) {
+	defer t.lock(0)()
+	fn(t.tree)
+}
@@ -0,0 +1,170 @@
+package syncbst_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/anderson"
+	"github.com/yanglinshu/bstrees/v2/avl"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+	"github.com/yanglinshu/bstrees/v2/fhq"
+	"github.com/yanglinshu/bstrees/v2/rb"
+	"github.com/yanglinshu/bstrees/v2/scapegoat"
+	"github.com/yanglinshu/bstrees/v2/splay"
+	"github.com/yanglinshu/bstrees/v2/syncbst"
+	"github.com/yanglinshu/bstrees/v2/treap"
+)
+
+var factories = []struct {
+	name string
+	new  func() bstrees.Tree[int]
+}{
+	{"AVL", func() bstrees.Tree[int] { return avl.New[int]() }},
+	{"RB", func() bstrees.Tree[int] { return rb.New[int]() }},
+	{"Anderson", func() bstrees.Tree[int] { return anderson.New[int]() }},
+	{"Treap", func() bstrees.Tree[int] { return treap.New[int]() }},
+	{"FHQ", func() bstrees.Tree[int] { return fhq.New[int]() }},
+	{"Splay", func() bstrees.Tree[int] { return splay.New[int]() }},
+	{"SplayNoReadSplay", func() bstrees.Tree[int] { return splay.New[int](splay.NoReadSplay()) }},
+	{"TopDown", func() bstrees.Tree[int] { return splay.NewTopDown[int]() }},
+	{"TopDownNoReadSplay", func() bstrees.Tree[int] { return splay.NewTopDown[int](splay.NoReadSplay()) }},
+	{"ScapeGoat", func() bstrees.Tree[int] { return scapegoat.New[int](0.7) }},
+}
+
+func TestTree(t *testing.T) {
+	for _, factory := range factories {
+		t.Run(factory.name, func(t *testing.T) {
+			bstreestest.RunSuite(t, func() bstrees.Tree[int] { return syncbst.New(factory.new()) })
+		})
+	}
+}
+
+// Run with -race to check which methods are read-only
+func TestConcurrent(t *testing.T) {
+	for _, factory := range factories {
+		t.Run(factory.name, func(t *testing.T) {
+			tree := syncbst.New(factory.new())
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 200; i++ {
+						value := g*1000 + i
+						if !tree.InsertIfAbsent(value) || tree.InsertIfAbsent(value) {
+							t.Errorf("InsertIfAbsent(%d) twice", value)
+						}
+						tree.Contains(value)
+						tree.Index(value)
+						tree.At(uint(i + 1))
+						tree.Predecessor(value)
+						tree.Successor(value)
+						tree.Floor(value)
+						tree.Ceiling(value)
+						tree.Min()
+						tree.Max()
+						tree.Size()
+						if i%2 == 0 && !tree.Replace(value, -value-1) {
+							t.Errorf("Replace(%d) = false", value)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 500; i++ {
+						value := (g*7 + i*13) % 8000
+						tree.Index(value)
+						tree.Predecessor(value)
+						tree.Successor(value)
+						tree.Contains(value)
+					}
+				}(g)
+			}
+			wg.Wait()
+			if size := tree.Size(); size != 8*200 {
+				t.Fatalf("Size() = %d, want %d", size, 8*200)
+			}
+			for g := 0; g < 8; g++ {
+				for i := 0; i < 200; i++ {
+					value := g*1000 + i
+					if i%2 == 0 {
+						value = -value - 1
+					}
+					if !tree.Contains(value) {
+						t.Fatalf("Contains(%d) = false", value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tree := syncbst.New[int](avl.New[int]())
+	tree.Insert(1)
+	if tree.Replace(2, 3) {
+		t.Error("Replace(2, 3) = true on a tree without 2")
+	}
+	if !tree.Replace(1, 3) || tree.Contains(1) || !tree.Contains(3) {
+		t.Error("Replace(1, 3) has not replaced 1 with 3")
+	}
+	tree.Do(func(tree bstrees.Tree[int]) {
+		tree.Insert(4)
+		tree.Delete(3)
+	})
+	if got, err := tree.At(1); err != nil || got != 4 {
+		t.Errorf("At(1) = %d, %v, want 4, nil", got, err)
+	}
+}
+
+func TestReplaceSet(t *testing.T) {
+	tree := syncbst.New[int](avl.New[int](avl.Unique()))
+	tree.Insert(1)
+	tree.Insert(2)
+	if tree.Replace(1, 2) {
+		t.Error("Replace(1, 2) = true on a set holding 2")
+	}
+	if !tree.Contains(1) || tree.Size() != 2 {
+		t.Error("Replace(1, 2) has modified the set")
+	}
+	if tree.InsertIfAbsent(2) || !tree.InsertIfAbsent(3) {
+		t.Error("InsertIfAbsent has not inserted 3 only")
+	}
+}
+
+func TestBounds(t *testing.T) {
+	for _, tree := range []bstrees.Tree[int]{avl.New[int](), struct{ bstrees.Tree[int] }{avl.New[int]()}} {
+		tree := syncbst.New(tree)
+		if _, ok := tree.Min(); ok {
+			t.Error("Min() found a value in an empty tree")
+		}
+		for _, value := range []int{2, 4, 6} {
+			tree.Insert(value)
+		}
+		for _, c := range []struct {
+			name  string
+			query func(value int) (int, bool)
+			value int
+			want  int
+			ok    bool
+		}{
+			{"Floor", tree.Floor, 4, 4, true},
+			{"Floor", tree.Floor, 5, 4, true},
+			{"Floor", tree.Floor, 1, 0, false},
+			{"Ceiling", tree.Ceiling, 4, 4, true},
+			{"Ceiling", tree.Ceiling, 5, 6, true},
+			{"Ceiling", tree.Ceiling, 7, 0, false},
+			{"Min", func(int) (int, bool) { return tree.Min() }, 0, 2, true},
+			{"Max", func(int) (int, bool) { return tree.Max() }, 0, 6, true},
+		} {
+			if got, ok := c.query(c.value); got != c.want || ok != c.ok {
+				t.Errorf("%s(%d) = %d, %t, want %d, %t", c.name, c.value, got, ok, c.want, c.ok)
+			}
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package treap
 
-import "golang.org/x/exp/constraints"
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
 
-func leftRotate[T constraints.Ordered](root *treapNode[T]) *treapNode[T] {
+func leftRotate[T any](root *treapNode[T], counter *stats.Counter) *treapNode[T] {
+	counter.Rotation()
 	right := root.right
 	root.right = right.left
 	right.left = root
@@ -11,7 +12,8 @@ func leftRotate[T constraints.Ordered](root *treapNode[T]) *treapNode[T] {
 	return right
 }
 
-func rightRotate[T constraints.Ordered](root *treapNode[T]) *treapNode[T] {
+func rightRotate[T any](root *treapNode[T], counter *stats.Counter) *treapNode[T] {
+	counter.Rotation()
 	left := root.left
 	root.left = left.right
 	left.right = root
@@ -19,3 +21,30 @@ func rightRotate[T constraints.Ordered](root *treapNode[T]) *treapNode[T] {
 	left.Update()
 	return left
 }
+
+// Build a treap from sorted values as a Cartesian tree on the random weights,
+// keeping the right spine in a stack.
+func fromSorted[T any](values []T) *treapNode[T] {
+	var stack []*treapNode[T]
+	for _, value := range values {
+		node := newTreapNode(value)
+		var last *treapNode[T] = nil
+		for len(stack) > 0 && stack[len(stack)-1].weight > node.weight {
+			last = stack[len(stack)-1]
+			last.Update()
+			stack = stack[:len(stack)-1]
+		}
+		node.left = last
+		if len(stack) > 0 {
+			stack[len(stack)-1].right = node
+		}
+		stack = append(stack, node)
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].Update()
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+	return stack[0]
+}
@@ -1,12 +1,8 @@
 package treap
 
-import (
-	"math/rand"
+import "math/rand"
 
-	"golang.org/x/exp/constraints"
-)
-
-type treapNode[T constraints.Ordered] struct {
+type treapNode[T any] struct {
 	value  T
 	left   *treapNode[T]
 	right  *treapNode[T]
@@ -14,7 +10,7 @@ type treapNode[T constraints.Ordered] struct {
 	size   uint // Size of subtree, unnecessary if you don't need kth element
 }
 
-func newTreapNode[T constraints.Ordered](value T) *treapNode[T] {
+func newTreapNode[T any](value T) *treapNode[T] {
 	return &treapNode[T]{value: value, left: nil, right: nil, weight: uint(rand.Uint32()), size: 1}
 }
 
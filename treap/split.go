@@ -0,0 +1,110 @@
+package treap
+
+import "github.com/yanglinshu/bstrees/v2/internal/stats"
+
+func merge[T any](left *treapNode[T], right *treapNode[T], counter *stats.Counter) *treapNode[T] {
+	counter.Merge()
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.weight < right.weight {
+		left.right = merge(left.right, right, counter)
+		left.Update()
+		return left
+	} else {
+		right.left = merge(left, right.left, counter)
+		right.Update()
+		return right
+	}
+}
+
+// Split root into values <= key and values > key
+func split[T any](root *treapNode[T], key T, cmp func(a, b T) int, counter *stats.Counter) (*treapNode[T], *treapNode[T]) {
+	counter.Split()
+	if root == nil {
+		return nil, nil
+	}
+	if cmp(root.value, key) <= 0 {
+		left, right := split(root.right, key, cmp, counter)
+		root.right = left
+		root.Update()
+		return root, right
+	} else {
+		left, right := split(root.left, key, cmp, counter)
+		root.left = right
+		root.Update()
+		return left, root
+	}
+}
+
+// Split root into values < key and values >= key
+func splitLess[T any](root *treapNode[T], key T, cmp func(a, b T) int, counter *stats.Counter) (*treapNode[T], *treapNode[T]) {
+	counter.Split()
+	if root == nil {
+		return nil, nil
+	}
+	if cmp(root.value, key) < 0 {
+		left, right := splitLess(root.right, key, cmp, counter)
+		root.right = left
+		root.Update()
+		return root, right
+	} else {
+		left, right := splitLess(root.left, key, cmp, counter)
+		root.left = right
+		root.Update()
+		return left, root
+	}
+}
+
+// Split root into the k smallest values and the others
+func splitSize[T any](root *treapNode[T], k uint, counter *stats.Counter) (*treapNode[T], *treapNode[T]) {
+	counter.Split()
+	if root == nil {
+		return nil, nil
+	}
+	leftSize := uint(0)
+	if root.left != nil {
+		leftSize = root.left.size
+	}
+	if leftSize < k {
+		left, right := splitSize(root.right, k-leftSize-1, counter)
+		root.right = left
+		root.Update()
+		return root, right
+	} else {
+		left, right := splitSize(root.left, k, counter)
+		root.left = right
+		root.Update()
+		return left, root
+	}
+}
+
+// SplitAt moves the values less than value to left and the others to right
+// in expected O(log n). t is left empty.
+func (t *Treap[T]) SplitAt(value T) (left, right *Treap[T]) {
+	l, r := splitLess(t.root, value, t.cmp, t.counter)
+	t.root = nil
+	return &Treap[T]{root: l, cmp: t.cmp, options: t.options, counter: t.counter}, &Treap[T]{root: r, cmp: t.cmp, options: t.options, counter: t.counter}
+}
+
+// SplitRank moves the k smallest values to left and the others to right in
+// expected O(log n). t is left empty.
+func (t *Treap[T]) SplitRank(k uint) (left, right *Treap[T]) {
+	l, r := splitSize(t.root, k, t.counter)
+	t.root = nil
+	return &Treap[T]{root: l, cmp: t.cmp, options: t.options, counter: t.counter}, &Treap[T]{root: r, cmp: t.cmp, options: t.options, counter: t.counter}
+}
+
+// Join moves the values of left and right to a new tree in expected
+// O(log n). Every value of left must be less than every value of right, or
+// equal to it if the trees are not sets, which is not checked. The new tree
+// is ordered like left, and left and right are left empty.
+func Join[T any](left, right *Treap[T]) *Treap[T] {
+	result := &Treap[T]{root: merge(left.root, right.root, left.counter), cmp: left.cmp, options: left.options, counter: left.counter}
+	left.root = nil
+	right.root = nil
+	return result
+}
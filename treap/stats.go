@@ -0,0 +1,15 @@
+package treap
+
+import "github.com/yanglinshu/bstrees/v2"
+
+// Stats returns the operations performed on t since it has been created or
+// since the last ResetStats. The trees made from t, such as the trees it is
+// split into, add to the same counts. Operations are only counted when the
+// module is built with the bstrees_stats tag, and cost nothing otherwise.
+func (t *Treap[T]) Stats() bstrees.Stats {
+	return t.counter.Stats()
+}
+
+func (t *Treap[T]) ResetStats() {
+	t.counter.Reset()
+}
@@ -2,18 +2,50 @@ package treap
 
 import (
 	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/internal/codec"
+	"github.com/yanglinshu/bstrees/v2/internal/stats"
 	"golang.org/x/exp/constraints"
 )
 
-type Treap[T constraints.Ordered] struct {
+var _ bstrees.Tree[int] = (*Treap[int])(nil)
+
+// Treap must be created by a constructor such as New or NewFunc.
+type Treap[T any] struct {
 	root *treapNode[T]
+	cmp  func(a, b T) int
+	options
+	counter *stats.Counter // Shared with the trees made from this one
+}
+
+func New[T constraints.Ordered](opts ...Option) *Treap[T] {
+	return NewFunc(bstrees.Compare[T], opts...)
+}
+
+// NewFunc creates a tree ordered by cmp, which returns a negative number when
+// a < b, a positive number when a > b and zero when a == b.
+func NewFunc[T any](cmp func(a, b T) int, opts ...Option) *Treap[T] {
+	counter := new(stats.Counter)
+	return &Treap[T]{root: nil, cmp: stats.Compare(counter, cmp), options: newOptions(opts), counter: counter}
+}
+
+// FromSorted builds a tree from values sorted in ascending order in O(n).
+// The order of values is not checked.
+func FromSorted[T constraints.Ordered](values []T, opts ...Option) *Treap[T] {
+	return FromSortedFunc(bstrees.Compare[T], values, opts...)
 }
 
-func New[T constraints.Ordered]() *Treap[T] {
-	return &Treap[T]{root: nil}
+// FromSortedFunc builds a tree ordered by cmp from values sorted by cmp in
+// O(n), see NewFunc and FromSorted.
+func FromSortedFunc[T any](cmp func(a, b T) int, values []T, opts ...Option) *Treap[T] {
+	t := NewFunc(cmp, opts...)
+	if t.unique {
+		values = codec.Unique(values, cmp)
+	}
+	t.root = fromSorted(values)
+	return t
 }
 
-func at[T constraints.Ordered](root *treapNode[T], k uint) *treapNode[T] {
+func at[T any](root *treapNode[T], k uint) *treapNode[T] {
 	for root != nil {
 		leftSize := uint(0)
 		if root.left != nil {
@@ -31,11 +63,11 @@ func at[T constraints.Ordered](root *treapNode[T], k uint) *treapNode[T] {
 	return nil
 }
 
-func search[T constraints.Ordered](root *treapNode[T], value T) *treapNode[T] {
+func search[T any](root *treapNode[T], value T, cmp func(a, b T) int) *treapNode[T] {
 	for root != nil {
-		if value < root.value {
+		if c := cmp(value, root.value); c < 0 {
 			root = root.left
-		} else if root.value < value {
+		} else if c > 0 {
 			root = root.right
 		} else {
 			return root
@@ -45,67 +77,82 @@ func search[T constraints.Ordered](root *treapNode[T], value T) *treapNode[T] {
 }
 
 func (t *Treap[T]) Contains(value T) bool {
-	return search(t.root, value) != nil
+	return search(t.root, value, t.cmp) != nil
 }
 
-func insert[T constraints.Ordered](root *treapNode[T], value T) *treapNode[T] {
+// Insert value into root, unless unique is set and value is already present.
+// Returns the new root, the node holding value and whether value has been
+// inserted.
+func insert[T any](root *treapNode[T], value T, cmp func(a, b T) int, unique bool, counter *stats.Counter) (*treapNode[T], *treapNode[T], bool) {
 	if root == nil {
-		return newTreapNode(value)
+		root = newTreapNode(value)
+		return root, root, true
 	}
-	if root.value <= value {
-		root.right = insert(root.right, value)
+	node := root
+	inserted := false
+	if c := cmp(root.value, value); c < 0 || (c == 0 && !unique) {
+		root.right, node, inserted = insert(root.right, value, cmp, unique, counter)
 		if root.right.weight < root.weight {
-			root = leftRotate(root)
+			root = leftRotate(root, counter)
 		}
-	} else {
-		root.left = insert(root.left, value)
+	} else if c > 0 {
+		root.left, node, inserted = insert(root.left, value, cmp, unique, counter)
 		if root.left.weight < root.weight {
-			root = rightRotate(root)
+			root = rightRotate(root, counter)
 		}
 	}
 	root.Update()
-	return root
+	return root, node, inserted
 }
 
-func (t *Treap[T]) Insert(value T) {
-	t.root = insert(t.root, value)
+func (t *Treap[T]) Insert(value T) bool {
+	var inserted bool
+	t.root, _, inserted = insert(t.root, value, t.cmp, t.unique, t.counter)
+	return inserted
 }
 
-func delete[T constraints.Ordered](root *treapNode[T], value T) *treapNode[T] {
+// Delete one copy of value from root, returns the new root and whether value
+// has been deleted
+func delete[T any](root *treapNode[T], value T, cmp func(a, b T) int, counter *stats.Counter) (*treapNode[T], bool) {
 	if root == nil {
-		return nil
+		return nil, false
 	}
-	if root.value == value {
+	deleted := true
+	if c := cmp(root.value, value); c == 0 {
 		if root.left == nil {
-			return root.right
+			return root.right, true
 		}
 		if root.right == nil {
-			return root.left
+			return root.left, true
 		}
 		if root.left.weight < root.right.weight {
-			root = rightRotate(root)
-			root.right = delete(root.right, value)
+			root = rightRotate(root, counter)
+			root.right, _ = delete(root.right, value, cmp, counter)
 		} else {
-			root = leftRotate(root)
-			root.left = delete(root.left, value)
+			root = leftRotate(root, counter)
+			root.left, _ = delete(root.left, value, cmp, counter)
 		}
-	} else if root.value < value {
-		root.right = delete(root.right, value)
+	} else if c < 0 {
+		root.right, deleted = delete(root.right, value, cmp, counter)
 	} else {
-		root.left = delete(root.left, value)
+		root.left, deleted = delete(root.left, value, cmp, counter)
+	}
+	if !deleted {
+		return root, false
 	}
 	root.Update()
-	return root
+	return root, true
 }
 
 func (t *Treap[T]) Delete(value T) {
-	t.root = delete(t.root, value)
+	t.root, _ = delete(t.root, value, t.cmp, t.counter)
 }
 
 func (t *Treap[T]) At(k uint) (T, error) {
 	result := at(t.root, k)
 	if result == nil {
-		return T(rune(0)), bstrees.ErrIndexIsOutOfRange
+		var zero T
+		return zero, bstrees.ErrIndexIsOutOfRange
 	}
 	return result.value, nil
 }
@@ -125,10 +172,10 @@ func (t *Treap[T]) Clear() {
 	t.root = nil
 }
 
-func index[T constraints.Ordered](root *treapNode[T], value T) uint {
+func index[T any](root *treapNode[T], value T, cmp func(a, b T) int) uint {
 	rank := uint(0)
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			rank += 1
 			if root.left != nil {
 				rank += root.left.size
@@ -142,13 +189,13 @@ func index[T constraints.Ordered](root *treapNode[T], value T) uint {
 }
 
 func (t *Treap[T]) Index(value T) uint {
-	return index(t.root, value)
+	return index(t.root, value, t.cmp)
 }
 
-func predecessor[T constraints.Ordered](root *treapNode[T], value T) *treapNode[T] {
+func predecessor[T any](root *treapNode[T], value T, cmp func(a, b T) int) *treapNode[T] {
 	var result *treapNode[T] = nil
 	for root != nil {
-		if root.value < value {
+		if cmp(root.value, value) < 0 {
 			result = root
 			root = root.right
 		} else {
@@ -159,17 +206,18 @@ func predecessor[T constraints.Ordered](root *treapNode[T], value T) *treapNode[
 }
 
 func (t *Treap[T]) Predecessor(value T) (T, error) {
-	result := predecessor(t.root, value)
+	result := predecessor(t.root, value, t.cmp)
 	if result == nil {
-		return T(rune(0)), bstrees.ErrPredecessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrPredecessorDoesNotExist
 	}
 	return result.value, nil
 }
 
-func successor[T constraints.Ordered](root *treapNode[T], value T) *treapNode[T] {
+func successor[T any](root *treapNode[T], value T, cmp func(a, b T) int) *treapNode[T] {
 	var result *treapNode[T] = nil
 	for root != nil {
-		if root.value > value {
+		if cmp(root.value, value) > 0 {
 			result = root
 			root = root.left
 		} else {
@@ -180,9 +228,186 @@ func successor[T constraints.Ordered](root *treapNode[T], value T) *treapNode[T]
 }
 
 func (t *Treap[T]) Successor(value T) (T, error) {
-	result := successor(t.root, value)
+	result := successor(t.root, value, t.cmp)
 	if result == nil {
-		return T(rune(0)), bstrees.ErrSuccessorDoesNotExist
+		var zero T
+		return zero, bstrees.ErrSuccessorDoesNotExist
 	}
 	return result.value, nil
 }
+
+func floor[T any](root *treapNode[T], value T, cmp func(a, b T) int) *treapNode[T] {
+	var result *treapNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c < 0 {
+			result = root
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return result
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (t *Treap[T]) Floor(value T) (T, bool) {
+	result := floor(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func ceiling[T any](root *treapNode[T], value T, cmp func(a, b T) int) *treapNode[T] {
+	var result *treapNode[T] = nil
+	for root != nil {
+		if c := cmp(root.value, value); c == 0 {
+			return root
+		} else if c > 0 {
+			result = root
+			root = root.left
+		} else {
+			root = root.right
+		}
+	}
+	return result
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (t *Treap[T]) Ceiling(value T) (T, bool) {
+	result := ceiling(t.root, value, t.cmp)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+func minimum[T any](root *treapNode[T]) *treapNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.left != nil {
+		root = root.left
+	}
+	return root
+}
+
+func maximum[T any](root *treapNode[T]) *treapNode[T] {
+	if root == nil {
+		return nil
+	}
+	for root.right != nil {
+		root = root.right
+	}
+	return root
+}
+
+// Remove the smallest node of a non-empty tree, returns the new root and the
+// removed node
+func deleteMin[T any](root *treapNode[T]) (*treapNode[T], *treapNode[T]) {
+	if root.left == nil {
+		return root.right, root
+	}
+	var removed *treapNode[T]
+	root.left, removed = deleteMin(root.left)
+	root.Update()
+	return root, removed
+}
+
+// Remove the greatest node of a non-empty tree, returns the new root and the
+// removed node
+func deleteMax[T any](root *treapNode[T]) (*treapNode[T], *treapNode[T]) {
+	if root.right == nil {
+		return root.left, root
+	}
+	var removed *treapNode[T]
+	root.right, removed = deleteMax(root.right)
+	root.Update()
+	return root, removed
+}
+
+// Min returns the smallest value.
+func (t *Treap[T]) Min() (T, bool) {
+	result := minimum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// Max returns the greatest value.
+func (t *Treap[T]) Max() (T, bool) {
+	result := maximum(t.root)
+	if result == nil {
+		var zero T
+		return zero, false
+	}
+	return result.value, true
+}
+
+// PopMin removes the smallest value and returns it.
+func (t *Treap[T]) PopMin() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed *treapNode[T]
+	t.root, removed = deleteMin(t.root)
+	return removed.value, true
+}
+
+// PopMax removes the greatest value and returns it.
+func (t *Treap[T]) PopMax() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	var removed *treapNode[T]
+	t.root, removed = deleteMax(t.root)
+	return removed.value, true
+}
+
+// Rank of the least value greater than value
+func upperIndex[T any](root *treapNode[T], value T, cmp func(a, b T) int) uint {
+	rank := uint(0)
+	for root != nil {
+		if cmp(root.value, value) <= 0 {
+			rank += 1
+			if root.left != nil {
+				rank += root.left.size
+			}
+			root = root.right
+		} else {
+			root = root.left
+		}
+	}
+	return rank + 1
+}
+
+// Count returns the number of copies of value.
+func (t *Treap[T]) Count(value T) uint {
+	first, last := t.EqualRange(value)
+	return last - first
+}
+
+// EqualRange returns the ranks [first, last) of the copies of value. When
+// value is absent, first == last is the rank value would be inserted at.
+func (t *Treap[T]) EqualRange(value T) (first, last uint) {
+	return index(t.root, value, t.cmp), upperIndex(t.root, value, t.cmp)
+}
+
+// DeleteAll removes every copy of value and returns how many were removed.
+func (t *Treap[T]) DeleteAll(value T) uint {
+	left, right := split(t.root, value, t.cmp, t.counter)
+	left, mid := splitLess(left, value, t.cmp, t.counter)
+	t.root = merge(left, right, t.counter)
+	if mid == nil {
+		return 0
+	}
+	return mid.size
+}
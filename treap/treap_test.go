@@ -0,0 +1,87 @@
+package treap_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yanglinshu/bstrees/v2"
+	"github.com/yanglinshu/bstrees/v2/bstreestest"
+	"github.com/yanglinshu/bstrees/v2/treap"
+)
+
+func TestTree(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] { return treap.New[int]() })
+}
+
+func TestTreeFunc(t *testing.T) {
+	bstreestest.RunSuite(t, func() bstrees.Tree[int] {
+		return treap.NewFunc(func(a, b int) int { return a - b })
+	})
+}
+
+func TestMap(t *testing.T) {
+	bstreestest.RunMapSuite(t, func() bstrees.Map[int, int] { return treap.NewMap[int, int]() })
+}
+
+func TestSet(t *testing.T) {
+	bstreestest.RunSetSuite(t, func() bstrees.Tree[int] { return treap.New[int](treap.Unique()) })
+}
+
+func TestFromSorted(t *testing.T) {
+	bstreestest.RunFromSortedSuite(t, func(values []int) bstrees.Tree[int] { return treap.FromSorted(values) })
+}
+
+func TestFromSortedUnique(t *testing.T) {
+	tree := treap.FromSorted([]int{1, 1, 2, 3, 3, 3}, treap.Unique())
+	if size := tree.Size(); size != 3 {
+		t.Errorf("Size() = %d, want 3", size)
+	}
+	if tree.Insert(2) {
+		t.Error("Insert(2) = true, want false")
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	bstreestest.RunSplitJoinSuite(t, func() *treap.Treap[int] { return treap.New[int]() },
+		(*treap.Treap[int]).SplitAt, (*treap.Treap[int]).SplitRank, treap.Join[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *treap.Treap[int] { return treap.New[int]() },
+		treap.Union[int], treap.Intersection[int], treap.Difference[int])
+}
+
+func TestSetAlgebraUnique(t *testing.T) {
+	bstreestest.RunSetAlgebraSuite(t, func() *treap.Treap[int] { return treap.New[int](treap.Unique()) },
+		treap.Union[int], treap.Intersection[int], treap.Difference[int])
+}
+
+func TestEncoding(t *testing.T) {
+	bstreestest.RunEncodingSuite(t, func() *treap.Treap[int] { return treap.New[int]() },
+		func() *treap.Treap[int] { return treap.New[int](treap.PreserveShape()) })
+}
+
+func TestString(t *testing.T) {
+	tree := treap.FromSorted([]int{1, 2, 3})
+	if got := tree.String(); strings.Count(got, "weight=") != 3 {
+		t.Errorf("String() = \n%s\nwant 3 weighted nodes", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := treap.New[int]()
+	data := `{"nodes":[{"value":1,"right":true,"weight":5},{"value":2,"weight":3}]}`
+	err := json.Unmarshal([]byte(data), tree)
+	if !errors.Is(err, bstrees.ErrDataIsCorrupted) || !strings.Contains(err.Error(), "node 1: child 2 has weight 3, less than 5") {
+		t.Errorf("Unmarshal() = %v, want %q", err, "node 1: child 2 has weight 3, less than 5")
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func BenchmarkRandom(b *testing.B) {
+	bstreestest.RunBenchmark(b, func() bstrees.Tree[int] { return treap.New[int]() })
+}
@@ -0,0 +1,35 @@
+package treap
+
+import "github.com/yanglinshu/bstrees/v2/internal/invariant"
+
+func validate[T any](root *treapNode[T], order *invariant.Order[T]) error {
+	if root == nil {
+		return nil
+	}
+	if err := validate(root.left, order); err != nil {
+		return err
+	}
+	if err := order.Next(root.value); err != nil {
+		return err
+	}
+	if err := validate(root.right, order); err != nil {
+		return err
+	}
+	if want := size(root.left) + size(root.right) + 1; root.size != want {
+		return invariant.Errorf(root.value, "size is %d, want %d", root.size, want)
+	}
+	for _, child := range []*treapNode[T]{root.left, root.right} {
+		if child != nil && child.weight < root.weight {
+			return invariant.Errorf(root.value, "child %v has weight %d, less than %d", child.value, child.weight, root.weight)
+		}
+	}
+	return nil
+}
+
+// Validate checks the order of the values and, for every node, its cached
+// size and that its weight is not greater than the ones of its children.
+// Returns a *bstrees.InvariantError naming the first node found to break a
+// rule.
+func (t *Treap[T]) Validate() error {
+	return validate(t.root, invariant.NewOrder(t.cmp, t.unique))
+}
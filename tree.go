@@ -0,0 +1,35 @@
+package bstrees
+
+// Tree is the interface shared by all the binary search trees in this module.
+// Ranks are 1-based, duplicated values are allowed unless the tree has been
+// created as a set.
+type Tree[T any] interface {
+	Insert(value T) bool // Whether value has been inserted, always true for a multiset
+	Delete(value T)
+	Contains(value T) bool
+	Size() uint
+	Empty() bool
+	Clear()
+	At(k uint) (T, error)           // k-th smallest value
+	Index(value T) uint             // Rank of value, i.e. number of values less than it plus one
+	Predecessor(value T) (T, error) // Greatest value strictly less than value
+	Successor(value T) (T, error)   // Least value strictly greater than value
+}
+
+// Map is the interface shared by the ordered maps in this module, which store
+// at most one value per key.
+type Map[K, V any] interface {
+	Put(key K, value V)
+	Get(key K) (V, bool)
+	Delete(key K) bool
+	GetOrInsert(key K, value V) (V, bool) // Value stored for key, and whether it was already present
+	Update(key K, fn func(value V, ok bool) V)
+	Contains(key K) bool
+	Size() uint
+	Empty() bool
+	Clear()
+	At(k uint) (K, V, error)
+	Index(key K) uint
+	Predecessor(key K) (K, V, error)
+	Successor(key K) (K, V, error)
+}